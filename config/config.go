@@ -11,16 +11,115 @@ type Config struct {
 		SecretKey string `json:"secret_key"`
 		Region    string `json:"region"`
 	} `json:"tencent_cloud"`
+	OCR struct {
+		// 按顺序尝试的OCR服务：tencent、tesseract、http，默认只使用 tencent
+		// 前一个服务出错、超时或没有识别出任何域名时尝试下一个
+		Providers      []string `json:"providers"`
+		TimeoutSeconds int      `json:"timeout_seconds"` // 单次调用OCR服务的超时时间，长截图切图后每张小图分别计时，0 表示不限制
+		Tencent        struct {
+			// 腾讯云OCR接口：basic（默认）、accurate（高精度版，别名 high-precision）、efficient（精简版）、fast（高速版）
+			Action       string `json:"action"`
+			LanguageType string `json:"language_type"` // 识别语言，例如 zh、auto，只有 basic 接口支持
+			// 开启后上传的PDF文件直接提交给腾讯云OCR识别 pdf_page_number 指定的一页（默认第1页），不再逐页渲染，efficient 接口不支持
+			IsPdf         bool   `json:"is_pdf"`
+			PdfPageNumber uint64 `json:"pdf_page_number"`
+		} `json:"tencent"`
+		Tesseract struct {
+			Path           string `json:"path"`      // tesseract 命令路径，默认从PATH中查找
+			Languages      string `json:"languages"` // 识别语言，例如 eng+chi_sim，默认 eng
+			PSM            int    `json:"psm"`       // 页面分割模式，0 表示使用 tesseract 的默认值
+			TimeoutSeconds int    `json:"timeout_seconds"`
+		} `json:"tesseract"`
+		HTTP struct {
+			// 通用HTTP OCR接口，POST图片内容，返回 {"detections": [{"text", "confidence", "polygon"}]}
+			URL            string            `json:"url"`
+			Headers        map[string]string `json:"headers"` // 例如鉴权用的 Authorization
+			TimeoutSeconds int               `json:"timeout_seconds"`
+		} `json:"http"`
+		Tiling struct {
+			// 高度超过 tile_height 的长截图切分为互相重叠的小图分别识别，默认 4000 像素
+			TileHeight int `json:"tile_height"`
+			Overlap    int `json:"overlap"` // 相邻小图重叠的高度，需大于一行文字的高度，默认 200 像素
+			Workers    int `json:"workers"` // 并发识别的小图数量，默认 4
+		} `json:"tiling"`
+		Cache struct {
+			// 按图片内容缓存OCR结果，配置数据库时同时缓存到数据库中
+			Enabled    bool `json:"enabled"`
+			TTLHours   int  `json:"ttl_hours"`   // 缓存有效期，默认 168 小时；没有识别出域名的结果最多缓存 1 小时
+			MaxEntries int  `json:"max_entries"` // 内存中最多缓存的结果数量，默认 1000
+		} `json:"cache"`
+		// 按OCR服务名称配置的图片预处理，例如 {"tesseract": {"grayscale": true, "binarize": true}}
+		Preprocess map[string]Preprocess `json:"preprocess"`
+	} `json:"ocr"`
 	Server struct {
 		Port string `json:"port"`
 	} `json:"server"`
+	Upload struct {
+		// 上传图片的限制，为0时使用默认值：7MB、短边20像素、5000万像素
+		MaxImageBytes  int64 `json:"max_image_bytes"`
+		MinImageSide   int   `json:"min_image_side"`
+		MaxImagePixels int   `json:"max_image_pixels"`
+		// 高度超过 ocr.tiling.tile_height 的长截图切分后识别，每张小图仍受上面的像素限制，整张图片的限制默认为 32MB、6000万像素
+		MaxLongImageBytes  int64 `json:"max_long_image_bytes"`
+		MaxLongImagePixels int   `json:"max_long_image_pixels"`
+		// 批量上传的限制，为0时使用默认值：50张图片、压缩包及全部图片解压后的总大小100MB、并发识别4张
+		MaxImages       int   `json:"max_images"`
+		MaxArchiveBytes int64 `json:"max_archive_bytes"`
+		Workers         int   `json:"workers"`
+	} `json:"upload"`
 	Analysis struct {
 		TrafficThreshold int64 `json:"traffic_threshold"`
 		DaysThreshold    int   `json:"days_threshold"`
+		Workers          int   `json:"workers"` // 并发查询域名数据的worker数量
 	} `json:"analysis"`
+	PDF struct {
+		// 读取PDF需要安装 poppler-utils，文字层中有域名的页面直接提取文字，其他页面渲染为图片后OCR
+		PdftotextPath  string `json:"pdftotext_path"`
+		PdftoppmPath   string `json:"pdftoppm_path"`
+		DPI            int    `json:"dpi"`       // 渲染图片的分辨率，默认 200
+		MaxPages       int    `json:"max_pages"` // 允许的最大页数，超过时拒绝上传，默认 20
+		TimeoutSeconds int    `json:"timeout_seconds"`
+	} `json:"pdf"`
 	WebArchive struct {
 		ProxyURL string `json:"proxy_url"`
 	} `json:"web_archive"`
+	Domain struct {
+		// 本地公共后缀列表文件，存在时替换内嵌的列表，可通过 psl update 命令更新
+		PublicSuffixListPath string `json:"public_suffix_list_path"`
+	} `json:"domain"`
+	Correction struct {
+		// 对OCR识别出的候选域名进行混淆字符纠错（0/o、1/l、rn/m、5/s、丢失的连字符等）
+		Enabled     bool `json:"enabled"`
+		Verify      bool `json:"verify"`       // 使用 Web Archive 验证纠正后的域名是否真实存在过
+		MaxVariants int  `json:"max_variants"` // 每个候选片段最多生成的候选拼写数量
+		MaxVerify   int  `json:"max_verify"`   // 每个候选片段最多验证的候选拼写数量
+		Workers     int  `json:"workers"`      // 并发纠错的候选片段数量，验证时每个候选拼写都要查询 Web Archive
+	} `json:"correction"`
+	Database struct {
+		// 例如 jingb:domainresearch@tcp(127.0.0.1:3306)/domainresearch?charset=utf8mb4
+		// 为空时不保存分析结果
+		DSN          string `json:"dsn"`
+		MaxOpenConns int    `json:"max_open_conns"`
+		MaxIdleConns int    `json:"max_idle_conns"`
+	} `json:"database"`
+	SimilarWeb struct {
+		APIKey string `json:"api_key"`
+		// 以下为默认的流量查询参数
+		Granularity    string `json:"granularity"`
+		Country        string `json:"country"`
+		Months         int    `json:"months"` // 查询最近多少个完整月份的数据
+		MainDomainOnly bool   `json:"main_domain_only"`
+		ShowVerified   bool   `json:"show_verified"`
+	} `json:"similar_web"`
+}
+
+// Preprocess 调用OCR服务前的图片预处理选项
+type Preprocess struct {
+	Grayscale bool   `json:"grayscale"`
+	Contrast  bool   `json:"contrast"`  // 对比度拉伸，改善彩色背景上的浅灰色文字
+	Binarize  bool   `json:"binarize"`  // 使用大津法二值化
+	MinWidth  int    `json:"min_width"` // 宽度小于该值的图片放大到该宽度，最多放大4倍且不超过图片尺寸限制，0 表示不放大
+	Invert    string `json:"invert"`    // 暗色模式反色：auto 根据平均亮度判断，always 总是反色，为空时不反色，其他取值启动时报错
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"context"
+	"domain-analyzer/internal/pkg/errors"
+	"domain-analyzer/internal/repository"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/idna"
+)
+
+// ListAnalysesHandler 查询历史分析结果
+// GET /api/analyses?verdict=pass&archived_from=2010-01-01&archived_to=2015-01-01
+//
+//	&min_visits=1000&max_visits=50000&tld=cn&public_suffix=com.cn&sort=first_archived_at&order=asc&limit=20&cursor=...
+type ListAnalysesHandler struct {
+	repo repository.AnalysisRepository
+}
+
+func NewListAnalysesHandler(repo repository.AnalysisRepository) Handler {
+	return &ListAnalysesHandler{repo: repo}
+}
+
+func (h *ListAnalysesHandler) Handle(ctx context.Context, req *http.Request) (interface{}, error) {
+	filter, err := parseAnalysisFilter(req.URL.Query())
+	if err != nil {
+		return nil, err
+	}
+	return listAnalyses(ctx, h.repo, filter)
+}
+
+// DomainHistoryHandler 查询单个域名的历史分析结果
+// GET /api/domains/:name，支持与 /api/analyses 相同的查询参数
+type DomainHistoryHandler struct {
+	repo repository.AnalysisRepository
+}
+
+func NewDomainHistoryHandler(repo repository.AnalysisRepository) Handler {
+	return &DomainHistoryHandler{repo: repo}
+}
+
+// DomainHistoryResponse 单个域名的历史分析结果
+type DomainHistoryResponse struct {
+	Domain string `json:"domain"`
+	*repository.AnalysisPage
+}
+
+func (h *DomainHistoryHandler) Handle(ctx context.Context, req *http.Request) (interface{}, error) {
+	name := strings.ToLower(strings.TrimSpace(PathParam(ctx, "name")))
+	if name == "" {
+		return nil, errors.NewClientError("缺少域名参数", nil)
+	}
+	// 支持直接使用Unicode形式查询国际化域名
+	name, err := idna.Lookup.ToASCII(name)
+	if err != nil {
+		return nil, errors.NewClientError("域名格式不正确", err)
+	}
+
+	filter, err := parseAnalysisFilter(req.URL.Query())
+	if err != nil {
+		return nil, err
+	}
+	filter.Domain = name
+
+	page, err := listAnalyses(ctx, h.repo, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &DomainHistoryResponse{Domain: name, AnalysisPage: page}, nil
+}
+
+// listAnalyses 执行查询并区分客户端与服务端错误
+func listAnalyses(ctx context.Context, repo repository.AnalysisRepository, filter repository.AnalysisFilter) (*repository.AnalysisPage, error) {
+	page, err := repo.ListAnalyses(ctx, filter)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return nil, errors.NewClientError("分页游标无效", err)
+	}
+	if err != nil {
+		return nil, errors.NewServerError("查询历史分析结果失败", err)
+	}
+	return page, nil
+}
+
+// parseAnalysisFilter 解析查询参数
+func parseAnalysisFilter(query url.Values) (repository.AnalysisFilter, error) {
+	var filter repository.AnalysisFilter
+
+	switch v := query.Get("verdict"); v {
+	case "":
+	case "pass":
+		passed := true
+		filter.Passed = &passed
+	case "fail":
+		passed := false
+		filter.Passed = &passed
+	default:
+		return filter, errors.NewClientError("verdict 只支持 pass 或 fail", nil)
+	}
+
+	var err error
+	if filter.ArchivedFrom, err = parseDateParam(query, "archived_from"); err != nil {
+		return filter, err
+	}
+	if filter.ArchivedTo, err = parseDateParam(query, "archived_to"); err != nil {
+		return filter, err
+	}
+	if filter.MinVisits, err = parseFloatParam(query, "min_visits"); err != nil {
+		return filter, err
+	}
+	if filter.MaxVisits, err = parseFloatParam(query, "max_visits"); err != nil {
+		return filter, err
+	}
+	filter.TLD = strings.ToLower(strings.TrimPrefix(query.Get("tld"), "."))
+	filter.PublicSuffix = strings.ToLower(strings.TrimPrefix(query.Get("public_suffix"), "."))
+
+	switch sort := query.Get("sort"); sort {
+	case "", repository.SortByCreatedAt, repository.SortByFirstArchivedAt, repository.SortByMonthlyVisits:
+		filter.Sort = sort
+	default:
+		return filter, errors.NewClientError("sort 只支持 created_at、first_archived_at 或 monthly_visits", nil)
+	}
+	switch order := query.Get("order"); order {
+	case "", "desc":
+	case "asc":
+		filter.Asc = true
+	default:
+		return filter, errors.NewClientError("order 只支持 asc 或 desc", nil)
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return filter, errors.NewClientError("limit 必须为正整数", err)
+		}
+		filter.Limit = limit
+	}
+	filter.Cursor = query.Get("cursor")
+
+	return filter, nil
+}
+
+// parseDateParam 解析 YYYY-MM-DD 格式的日期参数
+func parseDateParam(query url.Values, name string) (*time.Time, error) {
+	v := query.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return nil, errors.NewClientError(name+" 必须为 YYYY-MM-DD 格式的日期", err)
+	}
+	return &t, nil
+}
+
+// parseFloatParam 解析数值参数
+func parseFloatParam(query url.Values, name string) (*float64, error) {
+	v := query.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, errors.NewClientError(name+" 必须为数字", err)
+	}
+	return &f, nil
+}
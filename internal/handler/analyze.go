@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"domain-analyzer/internal/pkg/errors"
+	"domain-analyzer/internal/repository"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const (
+	// maxAnalyzeBodySize 文本提交的请求体大小上限
+	maxAnalyzeBodySize = 1 << 20
+	// maxAnalyzeDomains 文本提交一次最多分析的域名数量，每个域名都要查询收录和流量数据
+	maxAnalyzeDomains = 200
+)
+
+// AnalyzeHandler 直接分析域名列表或粘贴的文本，不经过OCR
+// POST /api/analyze
+//   - Content-Type: application/json，请求体 {"domains": ["example.com"], "text": "..."}
+//   - Content-Type: text/plain，请求体为任意文本（如CSV导出、聊天记录）
+type AnalyzeHandler struct {
+	pipeline *AnalysisPipeline
+}
+
+func NewAnalyzeHandler(pipeline *AnalysisPipeline) Handler {
+	return &AnalyzeHandler{
+		pipeline: pipeline,
+	}
+}
+
+// AnalyzeRequest 文本分析请求
+type AnalyzeRequest struct {
+	Domains []string `json:"domains"`
+	Text    string   `json:"text"`
+}
+
+func (h *AnalyzeHandler) Handle(ctx context.Context, req *http.Request) (interface{}, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(nil, req.Body, maxAnalyzeBodySize))
+	if err != nil {
+		return nil, errors.NewClientError("请求体读取失败或超过大小限制", err)
+	}
+
+	var analyzeReq AnalyzeRequest
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		if err := json.Unmarshal(body, &analyzeReq); err != nil {
+			return nil, errors.NewClientError("解析JSON请求失败", err)
+		}
+	case "", "text/plain", "text/csv":
+		analyzeReq.Text = string(body)
+	default:
+		return nil, errors.NewClientError("不支持的Content-Type: "+mediaType, nil)
+	}
+
+	// 文本按行与域名列表一起交给域名提取，每行中的所有域名都会被识别
+	texts := append([]string{}, analyzeReq.Domains...)
+	for _, line := range strings.Split(analyzeReq.Text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			texts = append(texts, line)
+		}
+	}
+	if len(texts) == 0 {
+		return nil, errors.NewClientError("未提交任何域名或文本", nil)
+	}
+
+	return h.pipeline.run(ctx, texts, &repository.Upload{
+		Source: repository.UploadSourceText,
+	}, runOptions{debug: isDebug(req), maxDomains: maxAnalyzeDomains})
+}
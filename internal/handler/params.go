@@ -0,0 +1,16 @@
+package handler
+
+import "context"
+
+type pathParamsKey struct{}
+
+// WithPathParams 将路由中的路径参数写入context，供 Handler 读取
+func WithPathParams(ctx context.Context, params map[string]string) context.Context {
+	return context.WithValue(ctx, pathParamsKey{}, params)
+}
+
+// PathParam 从context中读取路径参数，不存在时返回空字符串
+func PathParam(ctx context.Context, name string) string {
+	params, _ := ctx.Value(pathParamsKey{}).(map[string]string)
+	return params[name]
+}
@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"context"
+	"domain-analyzer/internal/model"
+	"domain-analyzer/internal/pkg/domainutil"
+	"domain-analyzer/internal/pkg/errors"
+	"domain-analyzer/internal/pkg/logger"
+	"domain-analyzer/internal/repository"
+	"domain-analyzer/internal/service/analysis"
+	"domain-analyzer/internal/service/correction"
+	"domain-analyzer/internal/service/domain"
+	"domain-analyzer/internal/service/ocr"
+	"domain-analyzer/internal/service/table"
+	"fmt"
+	"math"
+	"net/http"
+	"unicode/utf8"
+)
+
+// AnalysisResponse 表示域名提取并分析后的响应结果
+type AnalysisResponse struct {
+	UploadID      int64                     `json:"upload_id,omitempty"`
+	OCRProvider   string                    `json:"ocr_provider,omitempty"`  // 产生OCR结果的服务名称
+	Preprocessing []string                  `json:"preprocessing,omitempty"` // 调用OCR服务前对图片执行的预处理步骤
+	Domains       []model.DomainAnalysis    `json:"domains"`
+	Diagnostics   *domainutil.ExtractReport `json:"diagnostics,omitempty"` // 仅在请求带 debug 参数时返回
+	Tables        []*table.Table            `json:"tables,omitempty"`      // 从每张图片的OCR结果中解析出的表格，仅在请求带 debug 参数时返回
+	Images        []ImageResult             `json:"images,omitempty"`      // 每张图片的识别结果
+}
+
+// AnalysisPipeline 图片上传与文本提交共用的处理流程：提取域名、纠错、分析、保存
+type AnalysisPipeline struct {
+	analyzer  *analysis.Analyzer
+	corrector *correction.Corrector         // 为nil时不进行OCR纠错
+	repo      repository.AnalysisRepository // 为nil时不保存分析结果
+}
+
+// NewAnalysisPipeline 创建域名分析流程，corrector 和 repo 均可为nil
+func NewAnalysisPipeline(analyzer *analysis.Analyzer, corrector *correction.Corrector, repo repository.AnalysisRepository) *AnalysisPipeline {
+	return &AnalysisPipeline{
+		analyzer:  analyzer,
+		corrector: corrector,
+		repo:      repo,
+	}
+}
+
+// runOptions 单次处理的选项
+type runOptions struct {
+	debug      bool            // 在响应和日志中输出域名提取的诊断信息
+	correct    bool            // 对OCR结果进行混淆字符纠错
+	exact      []bool          // 与文本一一对应，为true的文本内容准确（例如PDF文字层），不做纠错
+	detections []ocr.Detection // 与文本一一对应的OCR识别结果，提交文本时为nil
+	imageOf    []int           // 每行文本所属图片的下标，为nil时全部属于第一张图片
+	images     []string        // 上传的图片名称
+	maxDomains int             // 最多分析的域名数量，0 表示不限制
+}
+
+// run 从文本中提取域名并分析，upload 中的文本和域名字段由本方法填充
+func (p *AnalysisPipeline) run(ctx context.Context, texts []string, upload *repository.Upload, opts runOptions) (*AnalysisResponse, error) {
+	// 纠错验证和分析查询同一域名的收录情况时复用结果
+	ctx = domain.WithArchiveMemo(ctx)
+
+	// 从文本中提取域名
+	domains, report := domainutil.ExtractDomainsWithReport(texts)
+
+	// OCR混淆字符纠错
+	if opts.correct && p.corrector != nil {
+		var confidences []float64
+		for _, d := range opts.detections {
+			confidences = append(confidences, d.Confidence)
+		}
+		domains = p.corrector.Correct(ctx, domains, report, confidences, opts.exact)
+	}
+	logExtractReport(report, opts.debug)
+	if opts.maxDomains > 0 && len(domains) > opts.maxDomains {
+		return nil, errors.NewClientError(fmt.Sprintf("一次最多分析 %d 个域名，本次提交了 %d 个", opts.maxDomains, len(domains)), nil)
+	}
+
+	// 并发查询收录、流量数据并给出判定
+	analyses, err := p.analyzer.Analyze(ctx, domains)
+	if err != nil {
+		return nil, err
+	}
+
+	// 根据OCR结果的位置标出域名，并从表格中读取同一行的价格、出价次数等信息
+	var tables []*table.Table
+	if opts.detections != nil {
+		tables = parseTables(opts)
+		sources := domainImages(report, opts)
+		for i, d := range domains {
+			image := opts.imageIndex(d.TextIndex)
+			analyses[i].Location = locateDomain(opts.detections, d)
+			if analyses[i].Location != nil {
+				analyses[i].Location.Image = image
+			}
+			if image < len(tables) {
+				analyses[i].Metadata = tables[image].Metadata(d.TextIndex)
+			}
+			analyses[i].Images = sources[d.Host]
+		}
+	}
+	ret := &AnalysisResponse{OCRProvider: upload.OCRProvider, Domains: analyses}
+	if opts.debug {
+		ret.Diagnostics = report
+		ret.Tables = tables
+	}
+
+	// 保存分析结果，保存失败不影响本次响应
+	if p.repo != nil {
+		upload.OCRTexts = texts
+		for _, d := range domains {
+			upload.Domains = append(upload.Domains, d.Host)
+		}
+		if err := p.repo.SaveUpload(ctx, upload, analyses); err != nil {
+			logger.Errorf("save upload failed: %v", err)
+		} else {
+			ret.UploadID = upload.ID
+		}
+	}
+
+	return ret, nil
+}
+
+// imageIndex 返回指定文本所属图片的下标
+func (o runOptions) imageIndex(textIndex int) int {
+	if textIndex < 0 || textIndex >= len(o.imageOf) {
+		return 0
+	}
+	return o.imageOf[textIndex]
+}
+
+// parseTables 按图片分别解析表格，同一张图片的文本在 detections 中是连续的
+func parseTables(opts runOptions) []*table.Table {
+	detections := opts.detections
+	n := len(opts.images)
+	if n == 0 {
+		n = 1
+	}
+	tables := make([]*table.Table, n)
+	for start := 0; start < len(detections); {
+		image := opts.imageIndex(start)
+		end := start + 1
+		for end < len(detections) && opts.imageIndex(end) == image {
+			end++
+		}
+		if image < n {
+			tables[image] = table.Parse(detections[start:end], start)
+		}
+		start = end
+	}
+	// 没有识别出文字的图片对应空表格
+	for i := range tables {
+		if tables[i] == nil {
+			tables[i] = table.Parse(nil, 0)
+		}
+	}
+	return tables
+}
+
+// domainImages 根据提取报告统计每个域名出现过的图片，包括因重复被过滤的位置
+func domainImages(report *domainutil.ExtractReport, opts runOptions) map[string][]string {
+	sources := make(map[string][]string)
+	seen := make(map[string]map[int]bool)
+	for _, c := range report.Candidates {
+		host := c.Host
+		if c.CorrectedTo != "" {
+			host = c.CorrectedTo
+		} else if !c.Accepted && c.Reason != domainutil.RejectDuplicate {
+			continue
+		}
+		image := opts.imageIndex(c.TextIndex)
+		if image >= len(opts.images) || seen[host][image] {
+			continue
+		}
+		if seen[host] == nil {
+			seen[host] = make(map[int]bool)
+		}
+		seen[host][image] = true
+		sources[host] = append(sources[host], opts.images[image])
+	}
+	return sources
+}
+
+// locateDomain 根据域名在文本行中的字符偏移，从文本行的四点坐标中按比例截取域名所在的区域
+func locateDomain(detections []ocr.Detection, d *domainutil.Domain) *model.ImageLocation {
+	if d.TextIndex < 0 || d.TextIndex >= len(detections) || len(detections[d.TextIndex].Polygon) == 0 {
+		return nil
+	}
+	detection := detections[d.TextIndex]
+	location := &model.ImageLocation{Confidence: detection.Confidence, Polygon: detection.Polygon}
+
+	n := utf8.RuneCountInString(detection.Text)
+	if len(detection.Polygon) != 4 || n == 0 {
+		return location
+	}
+	from, to := float64(d.Start)/float64(n), float64(d.End)/float64(n)
+	tl, tr, br, bl := detection.Polygon[0], detection.Polygon[1], detection.Polygon[2], detection.Polygon[3]
+	location.Polygon = []model.Point{
+		interpolate(tl, tr, from),
+		interpolate(tl, tr, to),
+		interpolate(bl, br, to),
+		interpolate(bl, br, from),
+	}
+	return location
+}
+
+// interpolate 返回线段 a→b 上比例为 t 的点
+func interpolate(a, b model.Point, t float64) model.Point {
+	return model.Point{
+		X: a.X + int(math.Round(float64(b.X-a.X)*t)),
+		Y: a.Y + int(math.Round(float64(b.Y-a.Y)*t)),
+	}
+}
+
+// logExtractReport 记录被过滤的候选片段，debug 模式下以INFO级别输出全部诊断信息
+func logExtractReport(report *domainutil.ExtractReport, debug bool) {
+	log := logger.Debugf
+	if debug {
+		log = logger.Infof
+		for i, text := range report.Texts {
+			log("extract text[%d]: %q", i, text)
+		}
+	}
+	for _, c := range report.Candidates {
+		if !c.Accepted {
+			log("extract rejected text[%d] %q (host=%q): %s, corrected to %q", c.TextIndex, c.Candidate, c.Host, c.Reason, c.CorrectedTo)
+		}
+	}
+}
+
+// isDebug 判断请求是否开启了诊断模式，例如 /upload?debug=1
+func isDebug(req *http.Request) bool {
+	return queryFlag(req, "debug")
+}
+
+// isNoCache 判断请求是否要求跳过OCR缓存，例如 /upload?nocache=1
+func isNoCache(req *http.Request) bool {
+	return queryFlag(req, "nocache")
+}
+
+// queryFlag 判断查询参数是否为 1 或 true
+func queryFlag(req *http.Request, name string) bool {
+	switch req.URL.Query().Get(name) {
+	case "1", "true":
+		return true
+	}
+	return false
+}
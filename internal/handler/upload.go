@@ -1,31 +1,103 @@
 package handler
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
-	"domain-analyzer/internal/model"
+	"crypto/sha256"
+	"domain-analyzer/config"
 	"domain-analyzer/internal/pkg/errors"
-	"domain-analyzer/internal/service/domain"
+	"domain-analyzer/internal/pkg/logger"
+	"domain-analyzer/internal/repository"
 	"domain-analyzer/internal/service/ocr"
+	"domain-analyzer/internal/service/pdf"
+	"encoding/hex"
+	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
 )
 
-type UploadHandler struct {
-	ocrService ocr.OCRService
-	webArchive domain.WebArchive
+// pdfTextProvider PDF文字层的识别结果使用的服务名称
+const pdfTextProvider = "pdf-text"
+
+// 批量上传的默认限制
+const (
+	defaultMaxImages       = 50
+	defaultMaxArchiveBytes = 100 << 20
+	defaultUploadWorkers   = 4
+)
+
+// imageExtensions 压缩包中按扩展名识别的图片文件
+var imageExtensions = map[string]bool{
+	".png":  true,
+	".jpg":  true,
+	".jpeg": true,
+	".bmp":  true,
+	".gif":  true,
 }
 
-func NewUploadHandler(ocrService ocr.OCRService) Handler {
-	return &UploadHandler{
-		ocrService: ocrService,
-		webArchive: domain.GetWebArchive(),
-	}
+// ImageResult 表示一次上传中单张图片的识别结果
+type ImageResult struct {
+	Name          string   `json:"name"`
+	OCRProvider   string   `json:"ocr_provider,omitempty"`
+	Preprocessing []string `json:"preprocessing,omitempty"`
+	Lines         int      `json:"lines"`            // 识别出的文本行数
+	Cached        bool     `json:"cached,omitempty"` // OCR结果来自缓存
+	Error         string   `json:"error,omitempty"`  // 批量上传时单张图片校验或识别失败的原因
+}
+
+// uploadedImage 表示一张待识别的图片，压缩包中的图片名称为 "压缩包名/文件路径"，PDF的页面名称为 "文件名#page=页码"
+// PDF中有文字层的页面没有图片数据，result 直接由文字层生成；开启腾讯云PDF识别时 data 为整个PDF文件
+type uploadedImage struct {
+	name   string
+	data   []byte
+	pdf    bool
+	rawPDF bool
+	result *ocr.OCRResponse
+	err    error
 }
 
-// UploadResponse 表示图片上传并分析后的响应结果
-type UploadResponse struct {
-	Domains []model.DomainAnalysis `json:"domains"`
+type UploadHandler struct {
+	ocrService      ocr.OCRService
+	validator       *ocr.ImageValidator
+	pdf             *pdf.Extractor
+	pipeline        *AnalysisPipeline
+	maxImages       int
+	maxArchiveBytes int64
+	workers         int
+	tencent         *ocr.TencentOptions // 配置中的腾讯云OCR调用参数，OCR服务链中没有腾讯云OCR时为nil，不能按请求覆盖
+}
+
+// NewUploadHandler 创建图片上传处理器，支持一次上传多张图片、包含截图的ZIP压缩包或PDF文件
+func NewUploadHandler(config *config.Config, ocrService ocr.OCRService, pipeline *AnalysisPipeline) Handler {
+	h := &UploadHandler{
+		ocrService:      ocrService,
+		validator:       ocr.NewImageValidator(config),
+		pdf:             pdf.NewExtractor(config),
+		pipeline:        pipeline,
+		maxImages:       config.Upload.MaxImages,
+		maxArchiveBytes: config.Upload.MaxArchiveBytes,
+		workers:         config.Upload.Workers,
+	}
+	if ocr.UsesProvider(config, "tencent") {
+		options := ocr.NewTencentOptions(config)
+		h.tencent = &options
+	}
+	if h.maxImages <= 0 {
+		h.maxImages = defaultMaxImages
+	}
+	if h.maxArchiveBytes <= 0 {
+		h.maxArchiveBytes = defaultMaxArchiveBytes
+	}
+	if h.workers <= 0 {
+		h.workers = defaultUploadWorkers
+	}
+	return h
 }
 
 func (h *UploadHandler) Handle(ctx context.Context, req *http.Request) (interface{}, error) {
@@ -33,35 +105,374 @@ func (h *UploadHandler) Handle(ctx context.Context, req *http.Request) (interfac
 	if err := req.ParseMultipartForm(32 << 20); err != nil {
 		return nil, errors.NewClientError("解析上传请求失败", err)
 	}
+	// 例如 /upload?nocache=1，重新识别图片并刷新缓存
+	if isNoCache(req) {
+		ctx = ocr.WithCacheBypass(ctx)
+	}
+	// 开启腾讯云PDF识别时，PDF文件直接提交给腾讯云OCR识别其中一页，pdfPage 为0时逐页读取
+	var pdfPage uint64
+	if h.tencent != nil && h.tencent.PDF() {
+		pdfPage = h.tencent.PDFPage()
+	}
+	options, override, err := tencentOverride(req)
+	if err != nil {
+		return nil, err
+	}
+	if override {
+		if h.tencent == nil {
+			return nil, errors.NewClientError("ocr_action、ocr_language、ocr_is_pdf 和 ocr_pdf_page 只在使用腾讯云OCR时有效", nil)
+		}
+		// 与配置合并后再检查，例如配置了 accurate 接口时不能只指定识别语言
+		merged := h.tencent.Merge(options)
+		if err := merged.Validate(); err != nil {
+			return nil, err
+		}
+		pdfPage = 0
+		if merged.PDF() {
+			pdfPage = merged.PDFPage()
+		}
+		ctx = ocr.WithTencentOptions(ctx, options)
+	}
+
+	// image 字段可以包含多张图片或PDF文件，archive 字段为截图的ZIP压缩包
+	headers := append(req.MultipartForm.File["image"], req.MultipartForm.File["archive"]...)
+	if len(headers) == 0 {
+		return nil, errors.NewClientError("未找到上传的图片文件", http.ErrMissingFile)
+	}
 
-	file, _, err := req.FormFile("image")
+	images, err := h.readImages(ctx, headers, pdfPage)
 	if err != nil {
-		return nil, errors.NewClientError("未找到上传的图片文件", err)
+		return nil, err
+	}
+	if len(images) == 0 {
+		return nil, errors.NewClientError("上传的文件中没有图片或PDF页面", ocr.ErrEmptyImage)
 	}
-	defer file.Close()
 
-	// 读取图片数据
-	var buf bytes.Buffer
-	if _, err = io.Copy(&buf, file); err != nil {
-		return nil, errors.NewClientError("图片文件读取失败", err)
+	// 只有一张图片时，校验或识别失败直接返回错误
+	if len(images) == 1 {
+		return h.handleSingle(ctx, req, images[0])
 	}
+	return h.handleBatch(ctx, req, images)
+}
+
+// handleSingle 识别单张图片
+func (h *UploadHandler) handleSingle(ctx context.Context, req *http.Request, image *uploadedImage) (*AnalysisResponse, error) {
+	if image.result == nil {
+		if err := h.recognize(ctx, image); err != nil {
+			return nil, err
+		}
+	}
+	ocrResp := image.result
 
-	// 调用OCR服务识别域名
-	domains, err := h.ocrService.RecognizeDomains(ctx, buf.Bytes())
+	upload := &repository.Upload{Source: repository.UploadSourceImage, OCRProvider: ocrResp.Provider}
+	if image.pdf {
+		upload.Source = repository.UploadSourcePDF
+	} else {
+		hash := sha256.Sum256(image.data)
+		upload.ImageHash = hex.EncodeToString(hash[:])
+	}
+	resp, err := h.pipeline.run(ctx, ocrResp.Texts(), upload, runOptions{
+		debug:      isDebug(req),
+		correct:    ocrResp.Provider != pdfTextProvider, // PDF文字层的内容是准确的，只纠正OCR结果
+		detections: ocrResp.Detections,
+		images:     []string{image.name},
+	})
 	if err != nil {
 		return nil, err
 	}
+	resp.Preprocessing = ocrResp.Preprocessing
+	resp.Images = []ImageResult{imageResult(image)}
+	return resp, nil
+}
+
+// handleBatch 并发识别多张图片，合并全部文本后统一提取、去重和分析域名
+// 单张图片失败只记录在该图片的结果中，全部失败时返回第一个错误
+func (h *UploadHandler) handleBatch(ctx context.Context, req *http.Request, images []*uploadedImage) (*AnalysisResponse, error) {
+	var wg sync.WaitGroup
+	jobs := make(chan *uploadedImage)
+	workers := h.workers
+	if workers > len(images) {
+		workers = len(images)
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for image := range jobs {
+				if image.result == nil {
+					image.err = h.recognize(ctx, image)
+				}
+			}
+		}()
+	}
+	for _, image := range images {
+		jobs <- image
+	}
+	close(jobs)
+	wg.Wait()
+
+	// 按上传顺序合并识别结果，imageOf 记录每行文本所属的图片，exact 标记来自PDF文字层、不需要纠错的文本
+	var texts []string
+	var detections []ocr.Detection
+	var imageOf []int
+	var exact []bool
+	var firstErr error
+	providers := make(map[string]bool)
+	results := make([]ImageResult, 0, len(images))
+	names := make([]string, 0, len(images))
+	for i, image := range images {
+		results = append(results, imageResult(image))
+		names = append(names, image.name)
+		if image.err != nil {
+			if firstErr == nil {
+				firstErr = image.err
+			}
+			continue
+		}
+		providers[image.result.Provider] = true
+		for _, d := range image.result.Detections {
+			texts = append(texts, d.Text)
+			detections = append(detections, d)
+			imageOf = append(imageOf, i)
+			exact = append(exact, image.result.Provider == pdfTextProvider)
+		}
+	}
+	if len(providers) == 0 {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	upload := &repository.Upload{Source: repository.UploadSourceBatch}
+	if len(providers) == 1 {
+		for provider := range providers {
+			upload.OCRProvider = provider
+		}
+	}
+	resp, err := h.pipeline.run(ctx, texts, upload, runOptions{
+		debug:      isDebug(req),
+		correct:    true,
+		exact:      exact,
+		detections: detections,
+		imageOf:    imageOf,
+		images:     names,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp.Images = results
+	return resp, nil
+}
+
+// recognize 校验图片并调用OCR服务识别文字，无效图片不调用OCR服务
+func (h *UploadHandler) recognize(ctx context.Context, image *uploadedImage) error {
+	// 直接提交的PDF文件由腾讯云OCR检查大小和页码
+	if !image.rawPDF {
+		if _, err := h.validator.Validate(image.data); err != nil {
+			return err
+		}
+	}
+	result, err := h.ocrService.Recognize(ctx, image.data)
+	if err != nil {
+		return ocrError(err)
+	}
+	image.result = result
+	return nil
+}
 
-	ret := &UploadResponse{}
-	for _, domain := range domains {
-		analysisResult, err := h.webArchive.RecognizeDomains(ctx, domain)
+// readImages 读取上传的全部图片，ZIP压缩包会被展开；PDF文件在 pdfPage 为0时逐页读取，否则直接提交识别第 pdfPage 页
+// 全部图片数据（包括解压和渲染出的图片）的总大小不能超过 maxArchiveBytes
+func (h *UploadHandler) readImages(ctx context.Context, headers []*multipart.FileHeader, pdfPage uint64) ([]*uploadedImage, error) {
+	var images []*uploadedImage
+	var total int64
+	for _, header := range headers {
+		data, contentType, err := readFile(header, h.validator.MaxBytes(), h.maxArchiveBytes)
 		if err != nil {
 			return nil, err
 		}
-		ret.Domains = append(ret.Domains, model.DomainAnalysis{
-			Domain:             domain.Host,
-			WebArchiveResponse: analysisResult,
-		})
+
+		var entries []*uploadedImage
+		switch contentType {
+		case "application/zip":
+			if entries, err = h.readArchive(header.Filename, data, h.maxArchiveBytes-total); err != nil {
+				return nil, err
+			}
+		case "application/pdf":
+			if pdfPage > 0 {
+				entries = []*uploadedImage{{name: fmt.Sprintf("%s#page=%d", header.Filename, pdfPage), data: data, pdf: true, rawPDF: true}}
+			} else if entries, err = h.readPDF(ctx, header.Filename, data); err != nil {
+				return nil, err
+			}
+		default:
+			entries = []*uploadedImage{{name: header.Filename, data: data}}
+		}
+
+		for _, image := range entries {
+			total += int64(len(image.data))
+		}
+		if total > h.maxArchiveBytes {
+			return nil, errors.NewClientError(fmt.Sprintf("上传的文件总大小不能超过 %.1fMB", float64(h.maxArchiveBytes)/(1<<20)), nil)
+		}
+		images = append(images, entries...)
+		if len(images) > h.maxImages {
+			return nil, errors.NewClientError(fmt.Sprintf("一次最多上传 %d 张图片", h.maxImages), nil)
+		}
+	}
+	return images, nil
+}
+
+// readFile 读取上传的文件并返回按内容嗅探的类型
+// ZIP压缩包和PDF文件超过 archiveLimit 时返回错误；其他文件按图片处理，只读取到 imageLimit 为止，超出的由校验返回错误
+func readFile(header *multipart.FileHeader, imageLimit, archiveLimit int64) ([]byte, string, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, "", errors.NewClientError("图片文件读取失败", err)
+	}
+	defer file.Close()
+
+	// DetectContentType 最多使用前512字节
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, "", errors.NewClientError("图片文件读取失败", err)
+	}
+	head = head[:n]
+	contentType := http.DetectContentType(head)
+
+	archive := contentType == "application/zip" || contentType == "application/pdf"
+	limit := imageLimit
+	if archive {
+		limit = archiveLimit
+	}
+	buf := bytes.NewBuffer(head)
+	if _, err = io.Copy(buf, io.LimitReader(file, limit+1-int64(n))); err != nil {
+		return nil, "", errors.NewClientError("图片文件读取失败", err)
+	}
+	data := buf.Bytes()
+	if int64(len(data)) > limit {
+		if archive {
+			return nil, "", errors.NewClientError(fmt.Sprintf("文件 %s 过大", header.Filename), nil)
+		}
+		data = data[:limit+1]
+	}
+	return data, contentType, nil
+}
+
+// readArchive 展开ZIP压缩包中的图片，忽略目录、隐藏文件和非图片文件
+// 单个文件只读取到图片大小上限为止，超出的由校验返回错误；解压后的总大小不能超过 limit
+func (h *UploadHandler) readArchive(name string, data []byte, limit int64) ([]*uploadedImage, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.NewClientError("压缩包已损坏，无法读取", err)
+	}
+
+	var images []*uploadedImage
+	var total int64
+	for _, f := range reader.File {
+		base := path.Base(f.Name)
+		if f.FileInfo().IsDir() || strings.HasPrefix(f.Name, "__MACOSX/") || strings.HasPrefix(base, ".") ||
+			!imageExtensions[strings.ToLower(path.Ext(base))] {
+			continue
+		}
+		if len(images) >= h.maxImages {
+			return nil, errors.NewClientError(fmt.Sprintf("一次最多上传 %d 张图片", h.maxImages), nil)
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, errors.NewClientError("压缩包已损坏，无法读取", err)
+		}
+		var buf bytes.Buffer
+		_, err = io.Copy(&buf, io.LimitReader(rc, h.validator.MaxBytes()+1))
+		rc.Close()
+		if err != nil {
+			return nil, errors.NewClientError("压缩包已损坏，无法读取", err)
+		}
+
+		// 限制解压后的总大小，防止压缩炸弹
+		total += int64(buf.Len())
+		if total > limit {
+			return nil, errors.NewClientError("压缩包解压后过大", nil)
+		}
+		images = append(images, &uploadedImage{name: name + "/" + f.Name, data: buf.Bytes()})
+	}
+	return images, nil
+}
+
+// readPDF 读取PDF的每一页：文字层中有域名的页面直接使用文字，其他页面渲染为图片后再OCR
+func (h *UploadHandler) readPDF(ctx context.Context, name string, data []byte) ([]*uploadedImage, error) {
+	pages, err := h.pdf.Extract(ctx, data)
+	if err != nil {
+		if errors.Is(err, pdf.ErrToolNotFound) {
+			return nil, errors.NewServerError("服务器未安装PDF处理工具", err)
+		}
+		if errors.Is(err, pdf.ErrTooManyPages) {
+			return nil, errors.NewClientError(fmt.Sprintf("PDF文件 %s 超过 %d 页，请拆分后上传", name, h.pdf.MaxPages()), err)
+		}
+		return nil, errors.NewClientError("PDF文件无法读取", err)
+	}
+
+	images := make([]*uploadedImage, 0, len(pages))
+	for _, page := range pages {
+		image := &uploadedImage{name: fmt.Sprintf("%s#page=%d", name, page.Number), data: page.Image, pdf: true}
+		if page.Image == nil {
+			// 文字层的内容是准确的，置信度为100，没有位置信息
+			image.result = &ocr.OCRResponse{Provider: pdfTextProvider}
+			for _, line := range page.Lines {
+				image.result.Detections = append(image.result.Detections, ocr.Detection{Text: line, Confidence: 100})
+			}
+		}
+		images = append(images, image)
+	}
+	return images, nil
+}
+
+// imageResult 返回单张图片的识别结果摘要
+func imageResult(image *uploadedImage) ImageResult {
+	result := ImageResult{Name: image.name}
+	if image.err != nil {
+		logger.Warnf("recognize image %s failed: %v", image.name, image.err)
+		result.Error = errors.Message(image.err)
+	}
+	if image.result != nil {
+		result.OCRProvider = image.result.Provider
+		result.Preprocessing = image.result.Preprocessing
+		result.Lines = len(image.result.Detections)
+		result.Cached = image.result.Cached
+	}
+	return result
+}
+
+// ocrError 将OCR服务返回的未分类错误转换为客户端或服务端错误
+func ocrError(err error) error {
+	var e *errors.Error
+	if errors.As(err, &e) {
+		return err
+	}
+	if errors.Is(err, ocr.ErrEmptyImage) || errors.Is(err, ocr.ErrInvalidImage) {
+		return errors.NewClientError("图片无法识别，请检查图片格式", err)
+	}
+	return errors.NewServerError("OCR识别失败", err)
+}
+
+// tencentOverride 读取按请求覆盖的腾讯云OCR调用参数，第二个返回值表示是否有覆盖
+// 例如 ?ocr_action=accurate&ocr_language=auto 对难以识别的截图使用更准确（也更贵）的接口，?ocr_is_pdf=1&ocr_pdf_page=2 直接识别PDF的第2页
+func tencentOverride(req *http.Request) (ocr.TencentOptions, bool, error) {
+	options := ocr.TencentOptions{Action: req.FormValue("ocr_action"), LanguageType: req.FormValue("ocr_language")}
+	if v := req.FormValue("ocr_is_pdf"); v != "" {
+		isPdf, err := strconv.ParseBool(v)
+		if err != nil {
+			return options, false, errors.NewClientError("ocr_is_pdf 只能为 1、0、true 或 false", err)
+		}
+		options.IsPdf = &isPdf
+	}
+	if v := req.FormValue("ocr_pdf_page"); v != "" {
+		page, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || page == 0 {
+			return options, false, errors.NewClientError("ocr_pdf_page 应为从1开始的页码", err)
+		}
+		options.PdfPageNumber = page
 	}
-	return ret, nil
+	return options, options != ocr.TencentOptions{}, nil
 }
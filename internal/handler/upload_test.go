@@ -0,0 +1,311 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"domain-analyzer/config"
+	"domain-analyzer/internal/model"
+	"domain-analyzer/internal/pkg/errors"
+	"domain-analyzer/internal/service/analysis"
+	"domain-analyzer/internal/service/ocr"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// tileRecorder 记录每次收到的图片高度，每张小图返回一行文字
+type tileRecorder struct {
+	mu      sync.Mutex
+	heights []int
+}
+
+func (r *tileRecorder) Recognize(ctx context.Context, imageBytes []byte) (*ocr.OCRResponse, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(imageBytes))
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.heights = append(r.heights, cfg.Height)
+	n := len(r.heights)
+	r.mu.Unlock()
+
+	return &ocr.OCRResponse{Detections: []ocr.Detection{{
+		Text:       fmt.Sprintf("第%d张小图", n),
+		Confidence: 99,
+		Polygon:    []model.Point{{X: 0, Y: 40}, {X: 100, Y: 40}, {X: 100, Y: 60}, {X: 0, Y: 60}},
+	}}}, nil
+}
+
+func TestUploadTallImageOverSingleImageLimits(t *testing.T) {
+	recorder := &tileRecorder{}
+	ocr.Register("tile-recorder", func(*config.Config) (ocr.OCRService, error) {
+		return recorder, nil
+	})
+
+	cfg := &config.Config{}
+	cfg.OCR.Providers = []string{"tile-recorder"}
+	cfg.OCR.Tiling.TileHeight = 100
+	cfg.OCR.Tiling.Overlap = 10
+	cfg.Upload.MaxImageBytes = 4 << 10
+	cfg.Upload.MaxImagePixels = 200 * 100
+
+	// 随机噪点图片几乎无法压缩，文件大小和像素数都超过单张图片的限制
+	const width, height = 200, 1000
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	rnd := rand.New(rand.NewSource(1))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(rnd.Intn(256)), G: uint8(rnd.Intn(256)), B: uint8(rnd.Intn(256)), A: 255})
+		}
+	}
+	var data bytes.Buffer
+	if err := png.Encode(&data, img); err != nil {
+		t.Fatal(err)
+	}
+	if int64(data.Len()) <= cfg.Upload.MaxImageBytes || width*height <= cfg.Upload.MaxImagePixels {
+		t.Fatalf("test image %d bytes, %d pixels is within single image limits", data.Len(), width*height)
+	}
+
+	service, err := ocr.NewOCRService(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewUploadHandler(cfg, service, NewAnalysisPipeline(analysis.NewAnalyzer(cfg), nil, nil))
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("image", "long.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data.Bytes())
+	form.Close()
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	result, err := h.Handle(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Handle() error: %v", err)
+	}
+	resp := result.(*AnalysisResponse)
+
+	if len(recorder.heights) < 2 {
+		t.Fatalf("ocr called %d times, want the image split into tiles", len(recorder.heights))
+	}
+	for _, tileHeight := range recorder.heights {
+		if tileHeight > cfg.OCR.Tiling.TileHeight {
+			t.Errorf("ocr received image of height %d, want at most %d", tileHeight, cfg.OCR.Tiling.TileHeight)
+		}
+	}
+	if len(resp.Images) != 1 || resp.Images[0].Lines != len(recorder.heights) {
+		t.Errorf("images = %+v, want 1 image with %d lines", resp.Images, len(recorder.heights))
+	}
+	if resp.OCRProvider != "tile-recorder" {
+		t.Errorf("ocr provider = %q, want tile-recorder", resp.OCRProvider)
+	}
+}
+
+// zipOf 生成包含指定文件的ZIP压缩包
+func zipOf(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, data := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write(data)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// multipartFiles 生成上传请求并返回解析后的文件
+func multipartFiles(t *testing.T, field string, files map[string][]byte) []*multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for name, data := range files {
+		part, err := form.CreateFormFile(field, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(data)
+	}
+	form.Close()
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if err := req.ParseMultipartForm(32 << 20); err != nil {
+		t.Fatal(err)
+	}
+	return req.MultipartForm.File[field]
+}
+
+func TestReadArchiveSkipsNonImages(t *testing.T) {
+	h := NewUploadHandler(&config.Config{}, nil, nil).(*UploadHandler)
+	data := zipOf(t, map[string][]byte{
+		"shots/a.png":            []byte("a"),
+		"shots/B.JPG":            []byte("b"),
+		"__MACOSX/shots/._a.png": []byte("resource fork"),
+		"shots/.hidden.png":      []byte("hidden"),
+		"shots/readme.txt":       []byte("text"),
+		"shots/":                 nil,
+	})
+
+	images, err := h.readArchive("shots.zip", data, 1<<20)
+	if err != nil {
+		t.Fatalf("readArchive() error: %v", err)
+	}
+	names := make(map[string]bool)
+	for _, image := range images {
+		names[image.name] = true
+	}
+	if len(images) != 2 || !names["shots.zip/shots/a.png"] || !names["shots.zip/shots/B.JPG"] {
+		t.Errorf("readArchive() = %v, want only the two images", names)
+	}
+}
+
+func TestReadArchiveLimits(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Upload.MaxImages = 2
+	h := NewUploadHandler(cfg, nil, nil).(*UploadHandler)
+
+	large := zipOf(t, map[string][]byte{"a.png": bytes.Repeat([]byte{0}, 600), "b.png": bytes.Repeat([]byte{0}, 600)})
+	if _, err := h.readArchive("large.zip", large, 1000); !errors.IsClientError(err) {
+		t.Errorf("readArchive() over the size limit error = %v, want client error", err)
+	}
+	if _, err := h.readArchive("large.zip", large, 1200); err != nil {
+		t.Errorf("readArchive() within the size limit error: %v", err)
+	}
+
+	many := zipOf(t, map[string][]byte{"a.png": nil, "b.png": nil, "c.png": nil})
+	if _, err := h.readArchive("many.zip", many, 1<<20); !errors.IsClientError(err) {
+		t.Errorf("readArchive() over the image count limit error = %v, want client error", err)
+	}
+
+	if _, err := h.readArchive("broken.zip", []byte("PK\x03\x04 broken"), 1<<20); !errors.IsClientError(err) {
+		t.Errorf("readArchive() on a broken archive error = %v, want client error", err)
+	}
+}
+
+func TestReadImagesLimits(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Upload.MaxImageBytes = 100
+	cfg.Upload.MaxLongImageBytes = 100
+	cfg.Upload.MaxArchiveBytes = 250
+	h := NewUploadHandler(cfg, nil, nil).(*UploadHandler)
+
+	// 普通图片只读取到图片大小上限，由校验返回错误
+	images, err := h.readImages(context.Background(), multipartFiles(t, "image", map[string][]byte{
+		"large.png": bytes.Repeat([]byte{1}, 200),
+	}), 0)
+	if err != nil {
+		t.Fatalf("readImages() error: %v", err)
+	}
+	if len(images) != 1 || len(images[0].data) != 101 {
+		t.Errorf("readImages() read %d bytes, want 101", len(images[0].data))
+	}
+
+	// 全部文件的总大小不能超过上限
+	_, err = h.readImages(context.Background(), multipartFiles(t, "image", map[string][]byte{
+		"a.png": bytes.Repeat([]byte{1}, 100),
+		"b.png": bytes.Repeat([]byte{1}, 100),
+		"c.png": bytes.Repeat([]byte{1}, 100),
+	}), 0)
+	if !errors.IsClientError(err) {
+		t.Errorf("readImages() over the total size limit error = %v, want client error", err)
+	}
+
+	// 压缩包中的图片计入总大小
+	_, err = h.readImages(context.Background(), multipartFiles(t, "image", map[string][]byte{
+		"a.png":     bytes.Repeat([]byte{1}, 100),
+		"shots.zip": zipOf(t, map[string][]byte{"b.png": bytes.Repeat([]byte{0}, 100), "c.png": bytes.Repeat([]byte{0}, 100)}),
+	}), 0)
+	if !errors.IsClientError(err) {
+		t.Errorf("readImages() with an archive over the total size limit error = %v, want client error", err)
+	}
+}
+
+func TestUploadRejectsInvalidTencentOptions(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.OCR.Providers = []string{"tencent"}
+	cfg.OCR.Tencent.Action = ocr.TencentActionAccurate
+	h := NewUploadHandler(cfg, nil, nil)
+
+	for _, query := range []string{"ocr_language=auto", "ocr_action=basic&ocr_language=english", "ocr_action=handwriting"} {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		form.Close()
+		req := httptest.NewRequest(http.MethodPost, "/upload?"+query, &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+
+		// 参数在读取文件之前检查，不会返回“未找到上传的图片文件”
+		_, err := h.Handle(context.Background(), req)
+		if errors.HTTPStatus(err) != http.StatusBadRequest || errors.Is(err, http.ErrMissingFile) {
+			t.Errorf("Handle(%s) error = %v, want 400 for the ocr options", query, err)
+		}
+	}
+}
+
+func TestReadImagesSendsPDFDirectly(t *testing.T) {
+	h := NewUploadHandler(&config.Config{}, nil, nil).(*UploadHandler)
+	data := []byte("%PDF-1.4\n% scanned domain list\n")
+
+	images, err := h.readImages(context.Background(), multipartFiles(t, "image", map[string][]byte{"list.pdf": data}), 2)
+	if err != nil {
+		t.Fatalf("readImages() error: %v", err)
+	}
+	if len(images) != 1 || !images[0].rawPDF || images[0].name != "list.pdf#page=2" || !bytes.Equal(images[0].data, data) {
+		t.Errorf("readImages() = %+v, want the pdf file submitted as page 2", images)
+	}
+}
+
+func TestTencentOverride(t *testing.T) {
+	tests := []struct {
+		query    string
+		override bool
+		valid    bool
+		isPdf    *bool
+		page     uint64
+	}{
+		{query: "", valid: true},
+		{query: "ocr_action=fast", override: true, valid: true},
+		{query: "ocr_is_pdf=1&ocr_pdf_page=3", override: true, valid: true, isPdf: boolPtr(true), page: 3},
+		{query: "ocr_is_pdf=false", override: true, valid: true, isPdf: boolPtr(false)},
+		{query: "ocr_is_pdf=yes"},
+		{query: "ocr_pdf_page=0"},
+		{query: "ocr_pdf_page=first"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/upload?"+tt.query, nil)
+		options, override, err := tencentOverride(req)
+		if !tt.valid {
+			if !errors.IsClientError(err) {
+				t.Errorf("tencentOverride(%s) error = %v, want client error", tt.query, err)
+			}
+			continue
+		}
+		if err != nil || override != tt.override {
+			t.Errorf("tencentOverride(%s) = %v, %v, want override %v", tt.query, override, err, tt.override)
+			continue
+		}
+		if (options.IsPdf == nil) != (tt.isPdf == nil) || (tt.isPdf != nil && *options.IsPdf != *tt.isPdf) || options.PdfPageNumber != tt.page {
+			t.Errorf("tencentOverride(%s) = %+v", tt.query, options)
+		}
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
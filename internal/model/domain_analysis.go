@@ -2,7 +2,64 @@ package model
 
 // DomainAnalysis 表示单个域名的分析结果
 type DomainAnalysis struct {
-	Domain                        string                        `json:"domain"`
+	Domain                        string                        `json:"domain"`             // ASCII(punycode)形式，用于查询各数据源
+	UnicodeDomain                 string                        `json:"unicode_domain"`     // Unicode形式，便于展示国际化域名
+	PublicSuffix                  string                        `json:"public_suffix"`      // 有效顶级域名 (eTLD)
+	RegistrableDomain             string                        `json:"registrable_domain"` // 可注册域名 (eTLD+1)
 	WebArchiveResponse            WebArchiveResponse            `json:"web_archive_response"`
 	TotalTrafficAndEngagementResp TotalTrafficAndEngagementResp `json:"total_traffic_and_engagement_response"`
+	CorrectedFrom                 string                        `json:"corrected_from,omitempty"` // 经过OCR纠错时为文本中的原始片段
+	Occurrence                    TextOccurrence                `json:"occurrence"`
+	Location                      *ImageLocation                `json:"location,omitempty"` // 从图片中识别时域名所在的位置
+	Metadata                      map[string]string             `json:"metadata,omitempty"` // 表格截图中与域名同一行的其他列，例如 price、bids、end_time
+	Images                        []string                      `json:"images,omitempty"`   // 出现过该域名的全部图片
+	Sources                       DomainSources                 `json:"sources"`
+	Verdict                       Verdict                       `json:"verdict"`
+}
+
+// TextOccurrence 表示域名在原始文本中出现的位置
+type TextOccurrence struct {
+	Text      string `json:"text"`
+	TextIndex int    `json:"text_index"` // 原始文本在OCR结果或提交文本中的下标
+	Start     int    `json:"start"`      // 字符偏移，左闭右开
+	End       int    `json:"end"`
+}
+
+// Point 表示图片中的一个像素坐标
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// ImageLocation 表示域名在图片中的位置及OCR置信度
+type ImageLocation struct {
+	Image      int     `json:"image"`      // 所在图片在本次上传的图片列表中的下标
+	Confidence float64 `json:"confidence"` // 所在文本行的OCR置信度 0~100
+	Polygon    []Point `json:"polygon"`    // 域名所在区域的四点坐标，顺序为左上、右上、右下、左下
+}
+
+// SourceState 表示单个数据源的查询状态
+type SourceState string
+
+const (
+	// SourceStateOK 查询成功并拿到数据
+	SourceStateOK SourceState = "ok"
+	// SourceStateNotFound 查询成功但数据源中没有该域名的数据，例如从未被收录
+	SourceStateNotFound SourceState = "not_found"
+	// SourceStateError 查询失败，例如超时或接口报错
+	SourceStateError SourceState = "error"
+	// SourceStateSkipped 数据源未启用，没有查询
+	SourceStateSkipped SourceState = "skipped"
+)
+
+// SourceStatus 表示单个数据源的查询状态及错误信息
+type SourceStatus struct {
+	State SourceState `json:"state"`
+	Error string      `json:"error,omitempty"`
+}
+
+// DomainSources 记录各数据源的查询状态
+type DomainSources struct {
+	WebArchive SourceStatus `json:"web_archive"`
+	SimilarWeb SourceStatus `json:"similar_web"`
 }
@@ -0,0 +1,26 @@
+package model
+
+// RuleStatus 表示单条判定规则的结果状态
+type RuleStatus string
+
+const (
+	// RuleStatusPass 规则通过
+	RuleStatusPass RuleStatus = "pass"
+	// RuleStatusFail 规则未通过
+	RuleStatusFail RuleStatus = "fail"
+	// RuleStatusSkip 缺少数据或未配置阈值，规则未参与判定
+	RuleStatusSkip RuleStatus = "skip"
+)
+
+// RuleResult 表示单条判定规则的结果及说明
+type RuleResult struct {
+	Rule    string     `json:"rule"`
+	Status  RuleStatus `json:"status"`
+	Message string     `json:"message"`
+}
+
+// Verdict 表示域名的最终判定结果
+type Verdict struct {
+	Passed  bool         `json:"passed"`
+	Reasons []RuleResult `json:"reasons"`
+}
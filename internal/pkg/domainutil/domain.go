@@ -4,6 +4,10 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
 )
 
 var (
@@ -12,73 +16,244 @@ var (
 	// 2. 必须包含至少一个点号
 	// 3. 不允许连续的点号或连字符
 	// 4. 不允许开头或结尾是连字符
-	domainRegex = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)+[a-zA-Z]{2,}$`)
-
-	// 常见的顶级域名后缀
-	commonTLDs = map[string]bool{
-		"com": true, "org": true, "net": true, "edu": true, "gov": true,
-		"ru": true, "cn": true, "uk": true, "jp": true, "de": true,
-		"fr": true, "br": true, "it": true, "pl": true, "in": true,
-		"info": true, "biz": true, "io": true, "co": true, "me": true,
-	}
+	// 5. 国际化域名先转换为punycode再校验，顶级域名允许 xn-- 形式
+	domainRegex = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)+([a-zA-Z]{2,}|xn--[a-zA-Z0-9-]{1,59})$`)
+
+	// candidateRegex 在一行文本中查找所有形如域名的片段：由点号连接的两个及以上标签
+	// 标签允许Unicode字母和数字以便识别国际化域名，点号包括OCR识别中文截图时常见的全角、半角句号
+	// URL的协议、路径、端口和邮箱的用户名部分不会被匹配进来
+	candidateRegex = regexp.MustCompile(`[\p{L}\p{N}\p{M}](?:[\p{L}\p{N}\p{M}-]*[\p{L}\p{N}\p{M}])?(?:[.。．｡][\p{L}\p{N}\p{M}](?:[\p{L}\p{N}\p{M}-]*[\p{L}\p{N}\p{M}])?)+`)
+
+	// OCR识别中文截图时常见的全角、半角句号，统一替换为点号
+	dotReplacer = strings.NewReplacer("。", ".", "．", ".", "｡", ".")
+
+	// idnaProfile 按 UTS-46 (非过渡处理, IDNA2008) 规范化国际化域名
+	idnaProfile = idna.Lookup
 )
 
-// ExtractDomains 从文本列表中提取合法的域名
-func ExtractDomains(texts []string) ([]*url.URL, error) {
-	var results []*url.URL
-	seen := make(map[string]bool) // 用于去重
+// Domain 表示从文本中提取出的域名
+type Domain struct {
+	Host         string `json:"host"`          // 完整的主机名(ASCII/punycode形式)，例如 www.example.co.uk、xn--fsqu00a.xn--fiqs8s
+	Unicode      string `json:"unicode"`       // 主机名的Unicode形式，例如 例子.中国；ASCII域名与 Host 相同
+	PublicSuffix string `json:"public_suffix"` // 有效顶级域名 (eTLD)，例如 co.uk
+	Registrable  string `json:"registrable"`   // 可注册域名 (eTLD+1)，例如 example.co.uk
 
-	for _, text := range texts {
-		// 预处理文本
-		text = strings.TrimSpace(text)
-		text = strings.ToLower(text)
+	// CorrectedFrom 域名经过OCR纠错时记录文本中的原始片段
+	CorrectedFrom string `json:"corrected_from,omitempty"`
 
-		// 如果文本不包含点号，跳过
-		if !strings.Contains(text, ".") {
-			continue
-		}
+	// 域名在原始文本中的位置，Start/End 为字符（rune）偏移，左闭右开
+	Text      string `json:"text"`
+	TextIndex int    `json:"text_index"` // 原始文本在输入列表中的下标
+	Start     int    `json:"start"`
+	End       int    `json:"end"`
+}
 
-		// 尝试解析为URL
-		u, err := url.Parse("http://" + text)
-		if err != nil {
-			continue
-		}
+// URL 返回只包含域名部分的URL对象
+func (d *Domain) URL() *url.URL {
+	return &url.URL{Scheme: "http", Host: d.Host}
+}
 
-		// 获取主机名部分
-		host := u.Hostname()
+// candidate 表示文本中一个形如域名的片段
+type candidate struct {
+	raw        string
+	start, end int          // 字符偏移
+	skip       RejectReason // 根据上下文判断不是域名时的原因
+}
 
-		// 如果已经处理过这个域名，跳过
-		if seen[host] {
-			continue
+// findCandidates 查找一行文本中所有形如域名的片段
+// 紧跟 @ 的片段是邮箱的用户名，前面是单个 / 的片段是URL路径，URL中 ? 或 # 之后的片段是查询参数，会被标记为跳过
+func findCandidates(text string) []candidate {
+	var candidates []candidate
+	for _, loc := range candidateRegex.FindAllStringIndex(text, -1) {
+		start, end := trimScriptBoundary(text, loc[0], loc[1])
+		c := candidate{
+			raw:   text[start:end],
+			start: utf8.RuneCountInString(text[:start]),
+			end:   utf8.RuneCountInString(text[:end]),
 		}
-
-		// 验证域名格式
-		if !domainRegex.MatchString(host) {
-			continue
+		if strings.HasPrefix(text[end:], "@") {
+			c.skip = RejectEmailLocalPart
+		} else if strings.HasSuffix(text[:start], "/") && !strings.HasSuffix(text[:start], "//") {
+			c.skip = RejectURLPath
+		} else if token := text[strings.LastIndexFunc(text[:start], unicode.IsSpace)+1 : start]; strings.ContainsAny(token, "?#") {
+			c.skip = RejectURLQuery
 		}
+		candidates = append(candidates, c)
+	}
+	return candidates
+}
 
-		// 验证顶级域名
-		parts := strings.Split(host, ".")
-		if len(parts) < 2 {
-			continue
+// trimScriptBoundary 去掉候选片段首尾与域名紧挨着的中文
+// 例如 "出售example.com" 的第一个标签会被匹配为 "出售example"，这里在汉字与ASCII字母数字相接处切开，只保留域名部分
+// 只有汉字在标签之外时才切开：第一个标签切开后的部分、最后一个标签切开前的部分不能再有汉字，
+// 因此 "qq邮箱.com" 这样混合汉字和字母的国际化域名保持不变；返回调整后的字节偏移
+func trimScriptBoundary(text string, start, end int) (int, int) {
+	raw := text[start:end]
+	first := strings.IndexFunc(raw, isDomainDot)
+	last := strings.LastIndexFunc(raw, isDomainDot)
+	if first < 0 {
+		return start, end
+	}
+	_, dotSize := utf8.DecodeRuneInString(raw[last:])
+
+	// 最后一个标签取第一处切换之前的部分
+	lastLabel := raw[last+dotSize:]
+	if cut := firstScriptBoundary(lastLabel); cut > 0 && !containsHan(lastLabel[:cut]) {
+		end = start + last + dotSize + cut
+	}
+	// 第一个标签取最后一处切换之后的部分
+	if cut := lastScriptBoundary(raw[:first]); cut > 0 && !containsHan(raw[cut:first]) {
+		start += cut
+	}
+	return start, end
+}
+
+// firstScriptBoundary 返回标签中第一处汉字与ASCII字母数字相接的字节偏移，没有时返回 -1
+func firstScriptBoundary(label string) int {
+	var prev rune = -1
+	for i, r := range label {
+		if prev >= 0 && isScriptBoundary(prev, r) {
+			return i
 		}
-		tld := parts[len(parts)-1]
-		if !commonTLDs[tld] {
-			continue
+		prev = r
+	}
+	return -1
+}
+
+// lastScriptBoundary 返回标签中最后一处汉字与ASCII字母数字相接的字节偏移，没有时返回 -1
+func lastScriptBoundary(label string) int {
+	cut := -1
+	var prev rune = -1
+	for i, r := range label {
+		if prev >= 0 && isScriptBoundary(prev, r) {
+			cut = i
 		}
+		prev = r
+	}
+	return cut
+}
+
+func containsHan(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool { return unicode.Is(unicode.Han, r) }) >= 0
+}
+
+func isScriptBoundary(a, b rune) bool {
+	return unicode.Is(unicode.Han, a) && isASCIIAlnum(b) || isASCIIAlnum(a) && unicode.Is(unicode.Han, b)
+}
 
-		// 域名长度检查
-		if len(host) < 3 || len(host) > 255 {
-			continue
+func isASCIIAlnum(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+func isDomainDot(r rune) bool {
+	return r == '.' || r == '。' || r == '．' || r == '｡'
+}
+
+// parseCandidate 规范化并校验候选片段，返回的 Domain 只填充域名相关字段
+// 校验失败时返回规范化后的主机名（如果已经得到）和拒绝原因
+func parseCandidate(psl *PublicSuffixList, raw string) (*Domain, string, RejectReason) {
+	text := dotReplacer.Replace(strings.ToLower(raw))
+
+	// 国际化域名规范化后转换为punycode
+	host, err := idnaProfile.ToASCII(text)
+	if err != nil {
+		return nil, "", RejectInvalidIDN
+	}
+
+	// 验证域名格式
+	if !domainRegex.MatchString(host) {
+		return nil, host, RejectInvalidFormat
+	}
+
+	// 域名长度检查
+	if len(host) < 3 || len(host) > 255 {
+		return nil, host, RejectInvalidLength
+	}
+
+	// 根据公共后缀列表验证顶级域名
+	suffix, registrable, err := psl.EffectiveTLDPlusOne(host)
+	if err != nil {
+		if _, ok := psl.PublicSuffix(host); ok {
+			return nil, host, RejectPublicSuffix
 		}
+		return nil, host, RejectUnknownSuffix
+	}
 
-		// 标记为已处理
-		seen[host] = true
+	unicodeHost, err := idnaProfile.ToUnicode(host)
+	if err != nil {
+		return nil, host, RejectInvalidIDN
+	}
+
+	return &Domain{
+		Host:         host,
+		Unicode:      unicodeHost,
+		PublicSuffix: suffix,
+		Registrable:  registrable,
+	}, host, ""
+}
 
-		// 创建新的URL对象（只保留域名部分）
-		domainURL, _ := url.Parse("http://" + host)
-		results = append(results, domainURL)
+// ParseDomain 规范化并校验单个域名，校验失败时返回拒绝原因
+// 返回的 Domain 不包含在文本中的位置信息
+func ParseDomain(raw string) (*Domain, RejectReason) {
+	d, _, reason := parseCandidate(publicSuffixList(), raw)
+	return d, reason
+}
+
+// ExtractDomains 从文本列表中提取合法的域名
+// 每行文本中出现的所有域名都会被提取，URL和邮箱地址只保留域名部分
+// 域名的后缀必须在公共后缀列表中，且不能是公共后缀本身；同一域名只保留第一次出现的位置
+func ExtractDomains(texts []string) ([]*Domain, error) {
+	return extractDomains(texts, nil), nil
+}
+
+// ExtractDomainsWithReport 与 ExtractDomains 相同，同时返回每个候选片段的处理结果，用于排查域名为何没有被识别
+func ExtractDomainsWithReport(texts []string) ([]*Domain, *ExtractReport) {
+	report := &ExtractReport{Texts: texts, Candidates: []CandidateResult{}}
+	return extractDomains(texts, report), report
+}
+
+// extractDomains 提取域名，report 不为nil时记录每个候选片段的处理结果
+func extractDomains(texts []string, report *ExtractReport) []*Domain {
+	var results []*Domain
+	seen := make(map[string]bool) // 用于去重
+	psl := publicSuffixList()
+
+	for i, text := range texts {
+		for _, c := range findCandidates(text) {
+			result := CandidateResult{
+				Candidate: c.raw,
+				TextIndex: i,
+				Start:     c.start,
+				End:       c.end,
+			}
+
+			var d *Domain
+			if c.skip != "" {
+				result.Reason = c.skip
+			} else {
+				d, result.Host, result.Reason = parseCandidate(psl, c.raw)
+			}
+
+			// 如果已经处理过这个域名，跳过
+			if d != nil && seen[d.Host] {
+				d, result.Reason = nil, RejectDuplicate
+			}
+
+			if d != nil {
+				seen[d.Host] = true
+				d.Text = text
+				d.TextIndex = i
+				d.Start = c.start
+				d.End = c.end
+				results = append(results, d)
+				result.Accepted = true
+			}
+
+			if report != nil {
+				report.Candidates = append(report.Candidates, result)
+			}
+		}
 	}
 
-	return results, nil
+	return results
 }
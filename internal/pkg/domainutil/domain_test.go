@@ -0,0 +1,49 @@
+package domainutil
+
+import "testing"
+
+func TestExtractDomainsSplitsChineseText(t *testing.T) {
+	tests := []struct {
+		text string
+		host string
+		from string
+	}{
+		{text: "出售example.com", host: "example.com", from: "example.com"},
+		{text: "example.com出售", host: "example.com", from: "example.com"},
+		{text: "出售example.com.cn，价格面议", host: "example.com.cn", from: "example.com.cn"},
+		{text: "网址：出售163.com。", host: "163.com", from: "163.com"},
+		{text: "例子.中国", host: "xn--fsqu00a.xn--fiqs8s", from: "例子.中国"},
+		{text: "qq邮箱.com", host: "xn--qq-ii1f545e.com", from: "qq邮箱.com"},
+		{text: "登录 https://example.com/login?next=evil.com", host: "example.com", from: "example.com"},
+		{text: "https://example.com/#evil.com", host: "example.com", from: "example.com"},
+	}
+	for _, tt := range tests {
+		domains, err := ExtractDomains([]string{tt.text})
+		if err != nil {
+			t.Fatalf("ExtractDomains(%q) error: %v", tt.text, err)
+		}
+		if len(domains) != 1 {
+			t.Fatalf("ExtractDomains(%q) = %d domains, want 1", tt.text, len(domains))
+		}
+		d := domains[0]
+		if d.Host != tt.host {
+			t.Errorf("ExtractDomains(%q) host = %q, want %q", tt.text, d.Host, tt.host)
+		}
+		if got := string([]rune(tt.text)[d.Start:d.End]); got != tt.from {
+			t.Errorf("ExtractDomains(%q) position = %q, want %q", tt.text, got, tt.from)
+		}
+	}
+}
+
+func TestExtractDomainsSkipsURLQuery(t *testing.T) {
+	_, report := ExtractDomainsWithReport([]string{"https://example.com/login?next=evil.com&ref=a.org"})
+	reasons := make(map[string]RejectReason)
+	for _, c := range report.Candidates {
+		reasons[c.Candidate] = c.Reason
+	}
+	for _, candidate := range []string{"evil.com", "a.org"} {
+		if reasons[candidate] != RejectURLQuery {
+			t.Errorf("candidate %q reason = %q, want %q", candidate, reasons[candidate], RejectURLQuery)
+		}
+	}
+}
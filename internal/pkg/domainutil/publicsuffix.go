@@ -0,0 +1,237 @@
+package domainutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 公共后缀列表 (Public Suffix List) 用于判断域名的有效顶级域名 (eTLD) 和可注册域名 (eTLD+1)
+// 参考: https://publicsuffix.org/list/
+// 这里只使用列表中的 ICANN 部分，github.io、blogspot.com 等私有后缀下的子域名无法注册，不在研究范围内
+
+// PublicSuffixListURL 公共后缀列表的官方下载地址
+const PublicSuffixListURL = "https://publicsuffix.org/list/public_suffix_list.dat"
+
+//go:embed public_suffix_list.dat
+var embeddedPublicSuffixList []byte
+
+// ErrNotRegistrable 域名的后缀不在公共后缀列表中，或域名本身就是公共后缀
+var ErrNotRegistrable = errors.New("domain is not registrable")
+
+// 规则类型，同一个后缀可能同时存在多种规则
+const (
+	ruleNormal    = 1 << iota // com、co.uk
+	ruleWildcard              // *.ck，以 ck 为键保存
+	ruleException             // !www.ck，以 www.ck 为键保存
+)
+
+// PublicSuffixList 解析后的公共后缀列表
+type PublicSuffixList struct {
+	rules map[string]int
+}
+
+var (
+	pslMu      sync.RWMutex
+	defaultPSL *PublicSuffixList
+)
+
+// ParsePublicSuffixList 解析公共后缀列表文件，只保留 ICANN 部分的规则
+func ParsePublicSuffixList(r io.Reader) (*PublicSuffixList, error) {
+	list := &PublicSuffixList{rules: make(map[string]int)}
+
+	inICANN := false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "// ===BEGIN ICANN DOMAINS==="):
+			inICANN = true
+			continue
+		case strings.HasPrefix(line, "// ===END ICANN DOMAINS==="):
+			inICANN = false
+			continue
+		case line == "" || strings.HasPrefix(line, "//") || !inICANN:
+			continue
+		}
+
+		// 规则只取第一个空白之前的部分
+		if idx := strings.IndexAny(line, " \t"); idx >= 0 {
+			line = line[:idx]
+		}
+		rule := strings.ToLower(line)
+
+		// 列表中的国际化后缀（如 中国）是Unicode形式，统一转换为punycode与 Domain.Host 比较
+		kind := ruleNormal
+		switch {
+		case strings.HasPrefix(rule, "!"):
+			kind, rule = ruleException, rule[1:]
+		case strings.HasPrefix(rule, "*."):
+			kind, rule = ruleWildcard, rule[2:]
+		}
+		if ascii, err := idnaProfile.ToASCII(rule); err == nil {
+			rule = ascii
+		}
+		list.rules[rule] |= kind
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read public suffix list failed: %w", err)
+	}
+	if len(list.rules) == 0 {
+		return nil, errors.New("public suffix list contains no ICANN rules")
+	}
+	return list, nil
+}
+
+// PublicSuffix 返回域名的有效顶级域名
+// 第二个返回值表示是否命中了列表中的规则；未命中时按默认规则返回最后一级标签
+func (l *PublicSuffixList) PublicSuffix(domain string) (string, bool) {
+	labels := strings.Split(domain, ".")
+	for i := range labels {
+		candidate := strings.Join(labels[i:], ".")
+		flags := l.rules[candidate]
+		switch {
+		case flags&ruleException != 0:
+			return strings.Join(labels[i+1:], "."), true
+		case flags&ruleNormal != 0:
+			return candidate, true
+		case i+1 < len(labels) && l.rules[strings.Join(labels[i+1:], ".")]&ruleWildcard != 0:
+			return candidate, true
+		}
+	}
+	return labels[len(labels)-1], false
+}
+
+// EffectiveTLDPlusOne 返回域名的有效顶级域名和可注册域名 (eTLD+1)
+// 后缀不在列表中或域名本身就是公共后缀时返回 ErrNotRegistrable
+func (l *PublicSuffixList) EffectiveTLDPlusOne(domain string) (suffix, registrable string, err error) {
+	suffix, ok := l.PublicSuffix(domain)
+	if !ok || len(domain) <= len(suffix) {
+		return suffix, "", ErrNotRegistrable
+	}
+
+	rest := strings.TrimSuffix(domain[:len(domain)-len(suffix)], ".")
+	if rest == "" {
+		return suffix, "", ErrNotRegistrable
+	}
+	if idx := strings.LastIndex(rest, "."); idx >= 0 {
+		rest = rest[idx+1:]
+	}
+	return suffix, rest + "." + suffix, nil
+}
+
+// InitPublicSuffixList 解析内嵌的公共后缀列表，应在程序启动时调用，解析失败时返回错误
+func InitPublicSuffixList() error {
+	list, err := ParsePublicSuffixList(bytes.NewReader(embeddedPublicSuffixList))
+	if err != nil {
+		return fmt.Errorf("parse embedded public suffix list failed: %w", err)
+	}
+	pslMu.Lock()
+	if defaultPSL == nil {
+		defaultPSL = list
+	}
+	pslMu.Unlock()
+	return nil
+}
+
+// publicSuffixList 返回当前使用的公共后缀列表，没有调用 InitPublicSuffixList 时在首次调用时解析内嵌的列表
+// 内嵌列表无法解析时使用空列表，所有域名都会因后缀未知被过滤
+func publicSuffixList() *PublicSuffixList {
+	pslMu.RLock()
+	list := defaultPSL
+	pslMu.RUnlock()
+	if list != nil {
+		return list
+	}
+
+	if err := InitPublicSuffixList(); err != nil {
+		pslMu.Lock()
+		if defaultPSL == nil {
+			defaultPSL = &PublicSuffixList{rules: make(map[string]int)}
+		}
+		pslMu.Unlock()
+	}
+	pslMu.RLock()
+	defer pslMu.RUnlock()
+	return defaultPSL
+}
+
+// LoadPublicSuffixList 使用本地文件替换内嵌的公共后缀列表
+func LoadPublicSuffixList(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	list, err := ParsePublicSuffixList(file)
+	if err != nil {
+		return err
+	}
+
+	pslMu.Lock()
+	defaultPSL = list
+	pslMu.Unlock()
+	return nil
+}
+
+// UpdatePublicSuffixList 从官方地址下载最新的公共后缀列表，校验后原子地写入本地文件并立即生效
+func UpdatePublicSuffixList(ctx context.Context, path string) error {
+	client := &http.Client{Timeout: 30 * time.Second}
+	req, err := http.NewRequestWithContext(ctx, "GET", PublicSuffixListURL, nil)
+	if err != nil {
+		return fmt.Errorf("create request failed: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("download public suffix list failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download public suffix list failed with status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read public suffix list failed: %w", err)
+	}
+	list, err := ParsePublicSuffixList(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	// 先写临时文件再重命名，避免其他进程读到写了一半的文件
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".public_suffix_list-*.dat")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	pslMu.Lock()
+	defaultPSL = list
+	pslMu.Unlock()
+	return nil
+}
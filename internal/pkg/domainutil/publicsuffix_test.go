@@ -0,0 +1,54 @@
+package domainutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInitPublicSuffixList(t *testing.T) {
+	if err := InitPublicSuffixList(); err != nil {
+		t.Fatalf("InitPublicSuffixList() error: %v", err)
+	}
+}
+
+func TestParsePublicSuffixListWithoutICANNRules(t *testing.T) {
+	if _, err := ParsePublicSuffixList(strings.NewReader("// comment only\ncom\n")); err == nil {
+		t.Fatal("ParsePublicSuffixList() without ICANN section returned no error")
+	}
+}
+
+func TestEffectiveTLDPlusOne(t *testing.T) {
+	list, err := ParsePublicSuffixList(strings.NewReader(`// ===BEGIN ICANN DOMAINS===
+com
+cn
+com.cn
+*.ck
+!www.ck
+// ===END ICANN DOMAINS===
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		domain, suffix, registrable string
+		wantErr                     bool
+	}{
+		{domain: "www.example.com", suffix: "com", registrable: "example.com"},
+		{domain: "example.com.cn", suffix: "com.cn", registrable: "example.com.cn"},
+		{domain: "foo.bar.ck", suffix: "bar.ck", registrable: "foo.bar.ck"},
+		{domain: "www.ck", suffix: "ck", registrable: "www.ck"},
+		{domain: "com.cn", wantErr: true},
+	}
+	for _, tt := range tests {
+		suffix, registrable, err := list.EffectiveTLDPlusOne(tt.domain)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("EffectiveTLDPlusOne(%q) returned no error", tt.domain)
+			}
+			continue
+		}
+		if err != nil || suffix != tt.suffix || registrable != tt.registrable {
+			t.Errorf("EffectiveTLDPlusOne(%q) = %q, %q, %v; want %q, %q", tt.domain, suffix, registrable, err, tt.suffix, tt.registrable)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package domainutil
+
+// RejectReason 候选片段没有被识别为域名的原因
+type RejectReason string
+
+const (
+	// RejectEmailLocalPart 片段是邮箱地址 @ 之前的用户名
+	RejectEmailLocalPart RejectReason = "email_local_part"
+	// RejectURLPath 片段位于URL的路径中
+	RejectURLPath RejectReason = "url_path"
+	// RejectURLQuery 片段位于URL的查询参数或片段标识中，例如 ?next=example.com
+	RejectURLQuery RejectReason = "url_query"
+	// RejectInvalidIDN 国际化域名无法按 UTS-46 规范化
+	RejectInvalidIDN RejectReason = "invalid_idn"
+	// RejectInvalidFormat 不符合域名格式
+	RejectInvalidFormat RejectReason = "invalid_format"
+	// RejectInvalidLength 域名长度不合法
+	RejectInvalidLength RejectReason = "invalid_length"
+	// RejectUnknownSuffix 后缀不在公共后缀列表中
+	RejectUnknownSuffix RejectReason = "unknown_suffix"
+	// RejectPublicSuffix 片段本身就是公共后缀，例如 co.uk
+	RejectPublicSuffix RejectReason = "public_suffix"
+	// RejectDuplicate 与前面已识别的域名重复
+	RejectDuplicate RejectReason = "duplicate"
+)
+
+// CandidateResult 单个候选片段的处理结果
+type CandidateResult struct {
+	Candidate string       `json:"candidate"`      // 文本中匹配到的原始片段
+	Host      string       `json:"host,omitempty"` // 规范化后的主机名
+	TextIndex int          `json:"text_index"`
+	Start     int          `json:"start"` // 字符偏移，左闭右开
+	End       int          `json:"end"`
+	Accepted  bool         `json:"accepted"`
+	Reason    RejectReason `json:"reason,omitempty"`
+
+	// CorrectedTo 经过OCR纠错后识别出的域名
+	CorrectedTo string `json:"corrected_to,omitempty"`
+}
+
+// ExtractReport 域名提取的诊断信息
+// 原始文本中没有出现的域名是OCR漏识别，出现在 Candidates 中但未被接受的域名是被过滤掉的
+type ExtractReport struct {
+	Texts      []string          `json:"texts"`
+	Candidates []CandidateResult `json:"candidates"`
+}
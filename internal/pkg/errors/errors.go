@@ -3,6 +3,7 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"net/http"
 )
 
 // ErrorType 错误类型
@@ -20,6 +21,7 @@ type Error struct {
 	Type    ErrorType // 错误类型
 	Message string    // 错误信息
 	Err     error     // 原始错误
+	Status  int       // HTTP状态码，为0时客户端错误使用400，服务端错误使用500
 }
 
 // Error 实现error接口
@@ -53,6 +55,36 @@ func NewServerError(message string, err error) *Error {
 	}
 }
 
+// WithStatus 指定错误对应的HTTP状态码，例如 429、503
+func (e *Error) WithStatus(status int) *Error {
+	e.Status = status
+	return e
+}
+
+// HTTPStatus 返回错误对应的HTTP状态码，非自定义错误按服务端错误处理
+func HTTPStatus(err error) int {
+	var e *Error
+	if !As(err, &e) {
+		return http.StatusInternalServerError
+	}
+	if e.Status != 0 {
+		return e.Status
+	}
+	if e.Type == ErrorTypeClient {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// Message 返回可以展示给用户的错误信息，不包含原始错误的细节；非自定义错误返回通用的服务端错误信息
+func Message(err error) string {
+	var e *Error
+	if !As(err, &e) {
+		return "Internal Server Error"
+	}
+	return e.Message
+}
+
 // IsClientError 判断是否为客户端错误
 func IsClientError(err error) bool {
 	var e *Error
@@ -81,3 +113,8 @@ func IsServerError(err error) bool {
 func As(err error, target interface{}) bool {
 	return errors.As(err, target)
 }
+
+// Is 包装标准库的errors.Is
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
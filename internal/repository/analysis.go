@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"domain-analyzer/internal/model"
+	"domain-analyzer/internal/service/verdict"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 上传来源
+const (
+	UploadSourceImage = "image" // 上传图片并OCR
+	UploadSourceText  = "text"  // 直接提交域名列表或文本
+	UploadSourceBatch = "batch" // 一次上传多张图片或ZIP压缩包，不记录图片哈希
+	UploadSourcePDF   = "pdf"   // 上传只有一页的PDF文件
+)
+
+// Upload 表示一次上传记录
+type Upload struct {
+	ID          int64     `json:"id"`
+	Source      string    `json:"source"`
+	ImageHash   string    `json:"image_hash,omitempty"`   // 图片内容的SHA-256，文本来源为空
+	OCRProvider string    `json:"ocr_provider,omitempty"` // 产生OCR结果的服务名称，文本来源为空
+	OCRTexts    []string  `json:"ocr_texts"`
+	Domains     []string  `json:"domains"` // 从OCR文本中提取出的域名
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AnalysisRepository 定义分析结果的存储接口
+type AnalysisRepository interface {
+	// SaveUpload 在同一事务中保存上传记录及其全部域名分析结果，成功后回填 upload.ID
+	SaveUpload(ctx context.Context, upload *Upload, analyses []model.DomainAnalysis) error
+	// ListAnalyses 按条件分页查询历史分析结果
+	ListAnalyses(ctx context.Context, filter AnalysisFilter) (*AnalysisPage, error)
+}
+
+// mysqlAnalysisRepository 基于MySQL的分析结果存储
+type mysqlAnalysisRepository struct {
+	db *sql.DB
+}
+
+// NewAnalysisRepository 创建基于MySQL的分析结果存储
+func NewAnalysisRepository(db *sql.DB) AnalysisRepository {
+	return &mysqlAnalysisRepository{db: db}
+}
+
+// SaveUpload 实现 AnalysisRepository 接口
+func (r *mysqlAnalysisRepository) SaveUpload(ctx context.Context, upload *Upload, analyses []model.DomainAnalysis) error {
+	if upload.CreatedAt.IsZero() {
+		upload.CreatedAt = time.Now().UTC()
+	}
+	if upload.Source == "" {
+		upload.Source = UploadSourceImage
+	}
+	var imageHash, ocrProvider sql.NullString
+	if upload.ImageHash != "" {
+		imageHash = sql.NullString{String: upload.ImageHash, Valid: true}
+	}
+	if upload.OCRProvider != "" {
+		ocrProvider = sql.NullString{String: upload.OCRProvider, Valid: true}
+	}
+
+	texts, err := json.Marshal(upload.OCRTexts)
+	if err != nil {
+		return fmt.Errorf("marshal ocr texts failed: %w", err)
+	}
+	domains, err := json.Marshal(upload.Domains)
+	if err != nil {
+		return fmt.Errorf("marshal domains failed: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		"INSERT INTO uploads (source, image_hash, ocr_provider, ocr_texts, domains, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		upload.Source, imageHash, ocrProvider, texts, domains, upload.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert upload failed: %w", err)
+	}
+	uploadID, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("get upload id failed: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO domain_analyses
+			(upload_id, domain, tld, public_suffix, verdict_passed, first_archived_at, monthly_visits,
+			 archive_state, traffic_state, result, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("prepare analysis insert failed: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, analysis := range analyses {
+		result, err := json.Marshal(analysis)
+		if err != nil {
+			return fmt.Errorf("marshal analysis of %s failed: %w", analysis.Domain, err)
+		}
+
+		var firstArchivedAt sql.NullTime
+		if !analysis.WebArchiveResponse.CreateTime.IsZero() {
+			firstArchivedAt = sql.NullTime{Time: analysis.WebArchiveResponse.CreateTime, Valid: true}
+		}
+		var monthlyVisits sql.NullFloat64
+		if visits, ok := verdict.MonthlyVisits(analysis.TotalTrafficAndEngagementResp); ok {
+			monthlyVisits = sql.NullFloat64{Float64: visits, Valid: true}
+		}
+
+		if _, err := stmt.ExecContext(ctx,
+			uploadID, analysis.Domain, tldOf(analysis.Domain), analysis.PublicSuffix, analysis.Verdict.Passed, firstArchivedAt, monthlyVisits,
+			analysis.Sources.WebArchive.State, analysis.Sources.SimilarWeb.State, result, upload.CreatedAt,
+		); err != nil {
+			return fmt.Errorf("insert analysis of %s failed: %w", analysis.Domain, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction failed: %w", err)
+	}
+	upload.ID = uploadID
+	return nil
+}
+
+// tldOf 返回域名的最后一级标签
+func tldOf(domain string) string {
+	if idx := strings.LastIndex(domain, "."); idx >= 0 {
+		return domain[idx+1:]
+	}
+	return domain
+}
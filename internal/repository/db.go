@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"domain-analyzer/config"
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	defaultMaxOpenConns = 10
+	defaultMaxIdleConns = 5
+)
+
+// Open 根据配置打开MySQL连接池并检查连通性
+func Open(config *config.Config) (*sql.DB, error) {
+	dsn, err := mysql.ParseDSN(config.Database.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("parse dsn failed: %w", err)
+	}
+	// 扫描DATETIME列需要parseTime，统一使用UTC存储时间
+	dsn.ParseTime = true
+	dsn.Loc = time.UTC
+
+	db, err := sql.Open("mysql", dsn.FormatDSN())
+	if err != nil {
+		return nil, fmt.Errorf("open database failed: %w", err)
+	}
+
+	maxOpen := config.Database.MaxOpenConns
+	if maxOpen <= 0 {
+		maxOpen = defaultMaxOpenConns
+	}
+	maxIdle := config.Database.MaxIdleConns
+	if maxIdle <= 0 {
+		maxIdle = defaultMaxIdleConns
+	}
+	db.SetMaxOpenConns(maxOpen)
+	db.SetMaxIdleConns(maxIdle)
+	db.SetConnMaxLifetime(time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ping database failed: %w", err)
+	}
+
+	return db, nil
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS domain_analyses;
+DROP TABLE IF EXISTS uploads;
@@ -0,0 +1,29 @@
+-- 上传记录：每次上传的图片及其OCR结果
+CREATE TABLE uploads (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    image_hash CHAR(64)        NOT NULL COMMENT '图片内容的SHA-256',
+    ocr_texts  JSON            NOT NULL,
+    domains    JSON            NOT NULL COMMENT '从OCR文本中提取出的域名',
+    created_at DATETIME(3)     NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_uploads_image_hash (image_hash),
+    KEY idx_uploads_created_at (created_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+-- 域名分析结果：每个域名一行，完整结果保存在result列中
+CREATE TABLE domain_analyses (
+    id                BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    upload_id         BIGINT UNSIGNED NOT NULL,
+    domain            VARCHAR(255)    NOT NULL,
+    verdict_passed    TINYINT(1)      NOT NULL,
+    first_archived_at DATETIME        NULL,
+    monthly_visits    DOUBLE          NULL,
+    archive_state     VARCHAR(16)     NOT NULL,
+    traffic_state     VARCHAR(16)     NOT NULL,
+    result            JSON            NOT NULL,
+    created_at        DATETIME(3)     NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_domain_analyses_upload_id (upload_id),
+    KEY idx_domain_analyses_domain (domain),
+    CONSTRAINT fk_domain_analyses_upload FOREIGN KEY (upload_id) REFERENCES uploads (id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,6 @@
+DROP INDEX idx_domain_analyses_tld ON domain_analyses;
+DROP INDEX idx_domain_analyses_monthly_visits ON domain_analyses;
+DROP INDEX idx_domain_analyses_first_archived_at ON domain_analyses;
+DROP INDEX idx_domain_analyses_created_at ON domain_analyses;
+
+ALTER TABLE domain_analyses DROP COLUMN tld;
@@ -0,0 +1,11 @@
+-- 按顶级域名过滤历史分析结果
+ALTER TABLE domain_analyses
+    ADD COLUMN tld VARCHAR(63) NOT NULL DEFAULT '' AFTER domain;
+
+UPDATE domain_analyses SET tld = SUBSTRING_INDEX(domain, '.', -1);
+
+-- 查询API的过滤与排序索引
+CREATE INDEX idx_domain_analyses_created_at ON domain_analyses (created_at, id);
+CREATE INDEX idx_domain_analyses_first_archived_at ON domain_analyses (first_archived_at, id);
+CREATE INDEX idx_domain_analyses_monthly_visits ON domain_analyses (monthly_visits, id);
+CREATE INDEX idx_domain_analyses_tld ON domain_analyses (tld);
@@ -0,0 +1,5 @@
+DELETE FROM uploads WHERE image_hash IS NULL;
+
+ALTER TABLE uploads
+    DROP COLUMN source,
+    MODIFY COLUMN image_hash CHAR(64) NOT NULL COMMENT '图片内容的SHA-256';
@@ -0,0 +1,4 @@
+-- 区分图片上传与直接提交的域名列表，后者没有图片哈希
+ALTER TABLE uploads
+    ADD COLUMN source VARCHAR(16) NOT NULL DEFAULT 'image' AFTER id,
+    MODIFY COLUMN image_hash CHAR(64) NULL COMMENT '图片内容的SHA-256';
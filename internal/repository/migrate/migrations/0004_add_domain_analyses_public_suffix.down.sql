@@ -0,0 +1,3 @@
+DROP INDEX idx_domain_analyses_public_suffix ON domain_analyses;
+
+ALTER TABLE domain_analyses DROP COLUMN public_suffix;
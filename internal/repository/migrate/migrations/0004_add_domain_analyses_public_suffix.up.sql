@@ -0,0 +1,8 @@
+-- 有效顶级域名（例如 com.cn），tld 列仍为域名的最后一个标签
+ALTER TABLE domain_analyses
+    ADD COLUMN public_suffix VARCHAR(255) NOT NULL DEFAULT '' AFTER tld;
+
+-- 之前的记录没有按公共后缀列表校验，退化为最后一个标签
+UPDATE domain_analyses SET public_suffix = tld;
+
+CREATE INDEX idx_domain_analyses_public_suffix ON domain_analyses (public_suffix);
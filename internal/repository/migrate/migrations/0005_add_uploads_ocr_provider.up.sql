@@ -0,0 +1,3 @@
+-- 记录实际产生OCR结果的服务，文本来源为空
+ALTER TABLE uploads
+    ADD COLUMN ocr_provider VARCHAR(32) NULL AFTER image_hash;
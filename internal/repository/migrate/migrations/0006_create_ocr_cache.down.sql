@@ -0,0 +1 @@
+DROP TABLE IF EXISTS ocr_cache;
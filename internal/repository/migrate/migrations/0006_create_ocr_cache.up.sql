@@ -0,0 +1,10 @@
+-- OCR结果缓存：按图片内容的SHA-256缓存识别结果，重复上传的截图不再调用OCR服务
+CREATE TABLE ocr_cache (
+    cache_key  CHAR(64)    NOT NULL,
+    provider   VARCHAR(32) NOT NULL,
+    response   JSON        NOT NULL,
+    created_at DATETIME(3) NOT NULL,
+    expires_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (cache_key),
+    KEY idx_ocr_cache_expires_at (expires_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"domain-analyzer/internal/service/ocr"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// purgeBatchSize 每次写入缓存时最多清理的过期记录数
+const purgeBatchSize = 100
+
+// mysqlOCRCache 基于MySQL的OCR结果缓存，多个实例之间共享
+type mysqlOCRCache struct {
+	db *sql.DB
+}
+
+// NewOCRCache 创建基于MySQL的OCR结果缓存
+func NewOCRCache(db *sql.DB) ocr.Cache {
+	return &mysqlOCRCache{db: db}
+}
+
+// Get 实现 ocr.Cache 接口
+func (c *mysqlOCRCache) Get(ctx context.Context, key string) (*ocr.OCRResponse, error) {
+	var data []byte
+	err := c.db.QueryRowContext(ctx,
+		"SELECT response FROM ocr_cache WHERE cache_key = ? AND expires_at > ?",
+		key, time.Now().UTC()).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query ocr cache failed: %w", err)
+	}
+
+	var resp ocr.OCRResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("unmarshal ocr cache failed: %w", err)
+	}
+	return &resp, nil
+}
+
+// Set 实现 ocr.Cache 接口，同时清理少量过期记录
+func (c *mysqlOCRCache) Set(ctx context.Context, key string, resp *ocr.OCRResponse, ttl time.Duration) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("marshal ocr cache failed: %w", err)
+	}
+
+	now := time.Now().UTC()
+	_, err = c.db.ExecContext(ctx,
+		"INSERT INTO ocr_cache (cache_key, provider, response, created_at, expires_at) VALUES (?, ?, ?, ?, ?) "+
+			"ON DUPLICATE KEY UPDATE provider = VALUES(provider), response = VALUES(response), created_at = VALUES(created_at), expires_at = VALUES(expires_at)",
+		key, resp.Provider, data, now, now.Add(ttl))
+	if err != nil {
+		return fmt.Errorf("insert ocr cache failed: %w", err)
+	}
+
+	if _, err := c.db.ExecContext(ctx, "DELETE FROM ocr_cache WHERE expires_at <= ? LIMIT ?", now, purgeBatchSize); err != nil {
+		return fmt.Errorf("purge ocr cache failed: %w", err)
+	}
+	return nil
+}
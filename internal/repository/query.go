@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"domain-analyzer/internal/model"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 排序字段
+const (
+	SortByCreatedAt       = "created_at"
+	SortByFirstArchivedAt = "first_archived_at"
+	SortByMonthlyVisits   = "monthly_visits"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+var (
+	// ErrInvalidCursor 分页游标无法解析或与排序方式不匹配
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	// 可为空的排序列使用哨兵值参与排序和游标比较：
+	// 从未收录的域名视为最晚收录，没有流量数据的域名视为流量最低
+	nullArchivedAt = time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)
+	nullVisits     = float64(-1)
+)
+
+// AnalysisFilter 历史分析结果的查询条件，零值字段表示不过滤
+type AnalysisFilter struct {
+	Domain       string
+	Passed       *bool
+	ArchivedFrom *time.Time // 首次收录时间下限（含）
+	ArchivedTo   *time.Time // 首次收录时间上限（不含）
+	MinVisits    *float64
+	MaxVisits    *float64
+	TLD          string // 域名的最后一个标签，例如 cn
+	PublicSuffix string // 有效顶级域名，例如 com.cn
+
+	Sort   string // 取值见 SortBy* 常量，默认按创建时间
+	Asc    bool   // 默认倒序
+	Limit  int
+	Cursor string // 上一页返回的 NextCursor
+}
+
+// StoredAnalysis 表示一条已保存的域名分析结果
+type StoredAnalysis struct {
+	ID        int64     `json:"id"`
+	UploadID  int64     `json:"upload_id"`
+	CreatedAt time.Time `json:"created_at"`
+	model.DomainAnalysis
+}
+
+// AnalysisPage 分页查询结果
+type AnalysisPage struct {
+	Items      []StoredAnalysis `json:"items"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+// cursor 记录上一页最后一行的排序值和ID
+type cursor struct {
+	Sort   string    `json:"s"`
+	ID     int64     `json:"id"`
+	Time   time.Time `json:"t,omitempty"`
+	Number float64   `json:"n,omitempty"`
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// sortExpr 返回排序字段对应的SQL表达式
+func sortExpr(sort string) (string, error) {
+	switch sort {
+	case SortByCreatedAt:
+		return "created_at", nil
+	case SortByFirstArchivedAt:
+		return fmt.Sprintf("COALESCE(first_archived_at, CAST('%s' AS DATETIME))", nullArchivedAt.Format("2006-01-02 15:04:05")), nil
+	case SortByMonthlyVisits:
+		return fmt.Sprintf("COALESCE(monthly_visits, %g)", nullVisits), nil
+	default:
+		return "", fmt.Errorf("unsupported sort field: %s", sort)
+	}
+}
+
+// ListAnalyses 实现 AnalysisRepository 接口，使用基于游标的分页
+func (r *mysqlAnalysisRepository) ListAnalyses(ctx context.Context, filter AnalysisFilter) (*AnalysisPage, error) {
+	if filter.Sort == "" {
+		filter.Sort = SortByCreatedAt
+	}
+	expr, err := sortExpr(filter.Sort)
+	if err != nil {
+		return nil, err
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	var (
+		conds []string
+		args  []interface{}
+	)
+	if filter.Domain != "" {
+		conds = append(conds, "domain = ?")
+		args = append(args, filter.Domain)
+	}
+	if filter.Passed != nil {
+		conds = append(conds, "verdict_passed = ?")
+		args = append(args, *filter.Passed)
+	}
+	if filter.ArchivedFrom != nil {
+		conds = append(conds, "first_archived_at >= ?")
+		args = append(args, *filter.ArchivedFrom)
+	}
+	if filter.ArchivedTo != nil {
+		conds = append(conds, "first_archived_at < ?")
+		args = append(args, *filter.ArchivedTo)
+	}
+	if filter.MinVisits != nil {
+		conds = append(conds, "monthly_visits >= ?")
+		args = append(args, *filter.MinVisits)
+	}
+	if filter.MaxVisits != nil {
+		conds = append(conds, "monthly_visits <= ?")
+		args = append(args, *filter.MaxVisits)
+	}
+	if filter.TLD != "" {
+		conds = append(conds, "tld = ?")
+		args = append(args, filter.TLD)
+	}
+	if filter.PublicSuffix != "" {
+		conds = append(conds, "public_suffix = ?")
+		args = append(args, filter.PublicSuffix)
+	}
+
+	op, order := "<", "DESC"
+	if filter.Asc {
+		op, order = ">", "ASC"
+	}
+
+	if filter.Cursor != "" {
+		c, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if c.Sort != filter.Sort {
+			return nil, ErrInvalidCursor
+		}
+		var value interface{}
+		switch filter.Sort {
+		case SortByMonthlyVisits:
+			value = c.Number
+		default:
+			value = c.Time
+		}
+		conds = append(conds, fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?))", expr, op, expr, op))
+		args = append(args, value, value, c.ID)
+	}
+
+	query := "SELECT id, upload_id, created_at, first_archived_at, monthly_visits, result FROM domain_analyses"
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT ?", expr, order, order)
+	// 多查一行用于判断是否还有下一页
+	args = append(args, limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query analyses failed: %w", err)
+	}
+	defer rows.Close()
+
+	page := &AnalysisPage{Items: []StoredAnalysis{}}
+	var last cursor
+	for rows.Next() {
+		var (
+			item            StoredAnalysis
+			firstArchivedAt sql.NullTime
+			monthlyVisits   sql.NullFloat64
+			result          []byte
+		)
+		if err := rows.Scan(&item.ID, &item.UploadID, &item.CreatedAt, &firstArchivedAt, &monthlyVisits, &result); err != nil {
+			return nil, fmt.Errorf("scan analysis failed: %w", err)
+		}
+
+		if len(page.Items) == limit {
+			page.NextCursor = encodeCursor(last)
+			break
+		}
+
+		if err := json.Unmarshal(result, &item.DomainAnalysis); err != nil {
+			return nil, fmt.Errorf("unmarshal analysis %d failed: %w", item.ID, err)
+		}
+		page.Items = append(page.Items, item)
+
+		last = cursor{Sort: filter.Sort, ID: item.ID}
+		switch filter.Sort {
+		case SortByCreatedAt:
+			last.Time = item.CreatedAt
+		case SortByFirstArchivedAt:
+			last.Time = nullArchivedAt
+			if firstArchivedAt.Valid {
+				last.Time = firstArchivedAt.Time
+			}
+		case SortByMonthlyVisits:
+			last.Number = nullVisits
+			if monthlyVisits.Valid {
+				last.Number = monthlyVisits.Float64
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate analyses failed: %w", err)
+	}
+
+	return page, nil
+}
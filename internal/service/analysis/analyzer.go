@@ -0,0 +1,139 @@
+package analysis
+
+import (
+	"context"
+	"domain-analyzer/config"
+	"domain-analyzer/internal/model"
+	"domain-analyzer/internal/pkg/domainutil"
+	"domain-analyzer/internal/pkg/logger"
+	"domain-analyzer/internal/service/domain"
+	"domain-analyzer/internal/service/verdict"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultWorkers 未配置并发数时使用的默认worker数量
+	defaultWorkers = 8
+)
+
+// Analyzer 负责对识别出的域名进行数据补全（收录、流量）并给出判定
+type Analyzer struct {
+	config     *config.Config
+	webArchive domain.WebArchive
+	similarWeb domain.SimilarWeb
+	verdict    *verdict.Engine
+	workers    int
+}
+
+// NewAnalyzer 根据配置创建域名分析器
+func NewAnalyzer(config *config.Config) *Analyzer {
+	a := &Analyzer{
+		config:     config,
+		webArchive: domain.GetWebArchive(),
+		verdict:    verdict.NewEngine(config),
+		workers:    config.Analysis.Workers,
+	}
+	if a.workers <= 0 {
+		a.workers = defaultWorkers
+	}
+	// 未配置API Key时不查询流量数据
+	if config.SimilarWeb.APIKey != "" {
+		a.similarWeb = domain.GetSimilarWeb(&domain.SimilarWebConfig{
+			APIKey: config.SimilarWeb.APIKey,
+		})
+	}
+	return a
+}
+
+// Analyze 使用固定数量的worker并发分析域名，返回结果与输入顺序一致
+// 单个数据源查询失败只记录在对应域名的 Sources 中，不影响其他域名；
+// 只有ctx被取消时才返回错误
+func (a *Analyzer) Analyze(ctx context.Context, domains []*domainutil.Domain) ([]model.DomainAnalysis, error) {
+	trafficQuery := domain.DefaultTrafficQuery(a.config, time.Now())
+	results := make([]model.DomainAnalysis, len(domains))
+
+	var wg sync.WaitGroup
+	jobs := make(chan int)
+	workers := a.workers
+	if workers > len(domains) {
+		workers = len(domains)
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				results[idx] = a.analyzeDomain(ctx, trafficQuery, domains[idx])
+			}
+		}()
+	}
+
+	// 分发任务，ctx被取消后不再分发
+dispatch:
+	for i := range domains {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// analyzeDomain 查询单个域名的收录和流量数据，并给出判定
+func (a *Analyzer) analyzeDomain(ctx context.Context, trafficQuery domain.TrafficQuery, extracted *domainutil.Domain) model.DomainAnalysis {
+	analysis := model.DomainAnalysis{
+		Domain:            extracted.Host,
+		UnicodeDomain:     extracted.Unicode,
+		PublicSuffix:      extracted.PublicSuffix,
+		RegistrableDomain: extracted.Registrable,
+		CorrectedFrom:     extracted.CorrectedFrom,
+		Occurrence: model.TextOccurrence{
+			Text:      extracted.Text,
+			TextIndex: extracted.TextIndex,
+			Start:     extracted.Start,
+			End:       extracted.End,
+		},
+	}
+	d := extracted.URL()
+
+	archive, err := a.webArchive.RecognizeDomains(ctx, d)
+	switch {
+	case err == nil:
+		analysis.WebArchiveResponse = archive
+		analysis.Sources.WebArchive = model.SourceStatus{State: model.SourceStateOK}
+	case errors.Is(err, domain.ErrNoArchive):
+		// 从未被收录是正常的查询结果
+		analysis.Sources.WebArchive = model.SourceStatus{State: model.SourceStateNotFound}
+	default:
+		logger.Warnf("web archive lookup failed for %s: %v", d.Host, err)
+		analysis.Sources.WebArchive = model.SourceStatus{State: model.SourceStateError, Error: err.Error()}
+	}
+
+	// 查询SimilarWeb流量数据
+	if a.similarWeb == nil {
+		analysis.Sources.SimilarWeb = model.SourceStatus{State: model.SourceStateSkipped}
+	} else if traffic, err := a.similarWeb.TotalTrafficAndEngagement(ctx, trafficQuery, d); errors.Is(err, domain.ErrNoTrafficData) {
+		analysis.Sources.SimilarWeb = model.SourceStatus{State: model.SourceStateNotFound}
+	} else if err != nil {
+		logger.Warnf("similarweb lookup failed for %s: %v", d.Host, err)
+		analysis.Sources.SimilarWeb = model.SourceStatus{State: model.SourceStateError, Error: err.Error()}
+	} else if len(traffic.Visits) == 0 {
+		analysis.Sources.SimilarWeb = model.SourceStatus{State: model.SourceStateNotFound}
+	} else {
+		analysis.TotalTrafficAndEngagementResp = traffic
+		analysis.Sources.SimilarWeb = model.SourceStatus{State: model.SourceStateOK}
+	}
+
+	// 根据配置的阈值给出判定结论
+	analysis.Verdict = a.verdict.Evaluate(&analysis)
+	return analysis
+}
@@ -0,0 +1,304 @@
+package correction
+
+import (
+	"context"
+	"domain-analyzer/config"
+	"domain-analyzer/internal/pkg/domainutil"
+	"domain-analyzer/internal/pkg/logger"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	// defaultMaxVariants 每个候选片段最多生成的候选拼写数量
+	defaultMaxVariants = 64
+	// defaultMaxVerify 每个候选片段最多验证的候选拼写数量
+	defaultMaxVerify = 3
+	// defaultWorkers 未配置并发数时使用的默认worker数量
+	defaultWorkers = 8
+	// maxEdits 最多替换的混淆字符数量
+	maxEdits = 2
+	// unknownConfidence OCR没有给出置信度时使用的默认值
+	unknownConfidence = 50
+	// trustedConfidence 不验证时，OCR置信度不低于该值的文本视为识别无误，不做纠错
+	trustedConfidence = 95
+)
+
+// Verifier 验证一个候选拼写是否为真实存在过的域名
+type Verifier interface {
+	Exists(ctx context.Context, d *domainutil.Domain) (bool, error)
+}
+
+// Corrector 对OCR识别出的候选域名进行混淆字符纠错
+// 对每个候选片段生成替换混淆字符、补全连字符后的拼写，按公共后缀校验、词典得分和OCR置信度排序，
+// 开启验证时只采用真实存在过的拼写，已识别且存在的域名不会被纠正
+type Corrector struct {
+	dict        *dictionary
+	verifier    Verifier // 为nil时不验证
+	maxVariants int
+	maxVerify   int
+	workers     int
+}
+
+// NewCorrector 根据配置创建纠错器，verifier 为nil时不验证候选拼写
+func NewCorrector(config *config.Config, verifier Verifier) *Corrector {
+	c := &Corrector{
+		dict:        newDictionary(embeddedWords),
+		maxVariants: config.Correction.MaxVariants,
+		maxVerify:   config.Correction.MaxVerify,
+		workers:     config.Correction.Workers,
+	}
+	if c.maxVariants <= 0 {
+		c.maxVariants = defaultMaxVariants
+	}
+	if c.maxVerify <= 0 {
+		c.maxVerify = defaultMaxVerify
+	}
+	if c.workers <= 0 {
+		c.workers = defaultWorkers
+	}
+	if config.Correction.Verify {
+		c.verifier = verifier
+	}
+	return c
+}
+
+// scored 经过校验和打分的候选拼写
+type scored struct {
+	variant
+	domain *domainutil.Domain
+	score  float64
+}
+
+// Correct 对提取结果进行纠错，返回纠错后的域名列表（保持在文本中出现的顺序）
+// confidences 为每行文本的OCR置信度(0~100)，exact 标记内容准确、不需要纠错的文本（例如PDF文字层），两者都可以为nil；
+// 纠错结果会记录到 report 中
+func (c *Corrector) Correct(ctx context.Context, domains []*domainutil.Domain, report *domainutil.ExtractReport, confidences []float64, exact []bool) []*domainutil.Domain {
+	type position struct{ textIndex, start int }
+	accepted := make(map[position]*domainutil.Domain, len(domains))
+	for _, d := range domains {
+		accepted[position{d.TextIndex, d.Start}] = d
+	}
+
+	// 验证候选拼写需要查询 Web Archive，各候选片段并发纠错
+	originals := make([]*domainutil.Domain, len(report.Candidates))
+	corrections := make([]*domainutil.Domain, len(report.Candidates))
+	var wg sync.WaitGroup
+	jobs := make(chan int)
+	for i := 0; i < c.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				cand := &report.Candidates[idx]
+				confidence := float64(unknownConfidence)
+				if cand.TextIndex < len(confidences) {
+					confidence = confidences[cand.TextIndex]
+				}
+				corrections[idx] = c.correct(ctx, report.Texts[cand.TextIndex], cand, originals[idx], confidence)
+			}
+		}()
+	}
+
+	// 分发任务，ctx被取消后不再分发，未纠错的候选片段保持原样
+	var pending []int
+	for i := range report.Candidates {
+		cand := &report.Candidates[i]
+		if cand.Accepted {
+			originals[i] = accepted[position{cand.TextIndex, cand.Start}]
+		} else if !correctable(cand) {
+			continue
+		}
+		if cand.TextIndex < len(exact) && exact[cand.TextIndex] {
+			continue
+		}
+		pending = append(pending, i)
+	}
+dispatch:
+	for _, i := range pending {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	var results []*domainutil.Domain
+	seen := make(map[string]bool)
+	add := func(d *domainutil.Domain) {
+		if !seen[d.Host] {
+			seen[d.Host] = true
+			results = append(results, d)
+		}
+	}
+	for i := range report.Candidates {
+		cand := &report.Candidates[i]
+		if corrected := corrections[i]; corrected != nil {
+			cand.CorrectedTo = corrected.Host
+			logger.Infof("ocr correction: %q -> %s", cand.Candidate, corrected.Host)
+			add(corrected)
+		} else if originals[i] != nil {
+			add(originals[i])
+		}
+	}
+
+	return results
+}
+
+// correctable 判断被过滤的候选片段是否值得纠错
+// 邮箱用户名、URL路径和查询参数、重复、公共后缀本身不是识别错误；各标签都不含字母的片段（如价格 120.50）也不纠错
+func correctable(cand *domainutil.CandidateResult) bool {
+	switch cand.Reason {
+	case domainutil.RejectEmailLocalPart, domainutil.RejectURLPath, domainutil.RejectURLQuery,
+		domainutil.RejectDuplicate, domainutil.RejectPublicSuffix:
+		return false
+	}
+	labels := strings.Split(strings.Map(normalizeDot, cand.Candidate), ".")
+	for _, label := range labels[:len(labels)-1] {
+		if strings.IndexFunc(label, unicode.IsLetter) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// correct 返回纠正后的域名，不需要或无法纠正时返回nil
+// original 为已识别的域名，被过滤的候选片段为nil
+func (c *Corrector) correct(ctx context.Context, text string, cand *domainutil.CandidateResult, original *domainutil.Domain, confidence float64) *domainutil.Domain {
+	// 没有验证时无法确认纠正结果，高置信度的文本保持原样
+	if c.verifier == nil && confidence >= trustedConfidence {
+		return nil
+	}
+
+	lower := strings.Map(normalizeDot, strings.ToLower(cand.Candidate))
+
+	var variants []variant
+	hyphen, hasHyphen := hyphenVariant(text, cand.Start, lower)
+	if hasHyphen {
+		variants = append(variants, hyphen)
+	}
+
+	// 紧贴着被误识别的连字符，几乎可以确定原本是一个域名
+	if hasHyphen && hyphen.cost < 1 && c.verifier == nil {
+		if ranked := c.rank(variants, confidence); len(ranked) > 0 {
+			return c.build(text, cand, ranked[0])
+		}
+	}
+
+	// 已识别的合法域名只有在开启验证且原拼写不存在时才尝试纠正，避免把真实域名改错
+	if original != nil && (c.verifier == nil || c.verify(ctx, original)) {
+		return nil
+	}
+
+	variants = append(variants, substitutionVariants(lower, maxEdits, c.maxVariants)...)
+	ranked := c.rank(variants, confidence)
+	if len(ranked) == 0 {
+		return nil
+	}
+
+	if c.verifier != nil {
+		for i := 0; i < len(ranked) && i < c.maxVerify; i++ {
+			if c.verify(ctx, ranked[i].domain) {
+				return c.build(text, cand, ranked[i])
+			}
+		}
+		return nil
+	}
+
+	// 不验证时，纠正后至少要和原拼写一样像由单词组成
+	best := ranked[0]
+	if c.dict.score(registrableLabel(best.domain)) < c.dict.score(labelAt(lower, best.domain)) {
+		return nil
+	}
+	return c.build(text, cand, best)
+}
+
+// rank 校验候选拼写并按得分从高到低排序
+// 可注册标签中仍有与字母相邻的数字说明只纠正了一部分，这样的拼写直接丢弃
+// 得分 = 2 × 可注册标签的单词覆盖率 − 编辑代价 × (0.5 + OCR置信度)，置信度越高越不倾向纠正
+func (c *Corrector) rank(variants []variant, confidence float64) []scored {
+	var ranked []scored
+	for _, v := range variants {
+		d, _ := domainutil.ParseDomain(v.text)
+		if d == nil || mixedDigits(registrableLabel(d)) > 0 {
+			continue
+		}
+		ranked = append(ranked, scored{
+			variant: v,
+			domain:  d,
+			score:   2*c.dict.score(registrableLabel(d)) - v.cost*(0.5+confidence/100),
+		})
+	}
+	// 得分相同时保持生成顺序，混淆字符表中靠前的替换更常见
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+	return ranked
+}
+
+// verify 验证域名是否存在，验证失败视为不存在
+func (c *Corrector) verify(ctx context.Context, d *domainutil.Domain) bool {
+	if c.verifier == nil {
+		return false
+	}
+	ok, err := c.verifier.Exists(ctx, d)
+	if err != nil {
+		logger.Warnf("verify corrected domain %s failed: %v", d.Host, err)
+		return false
+	}
+	return ok
+}
+
+// build 补全纠正后域名在文本中的位置信息
+func (c *Corrector) build(text string, cand *domainutil.CandidateResult, s scored) *domainutil.Domain {
+	d := s.domain
+	d.CorrectedFrom = cand.Candidate
+	d.Text = text
+	d.TextIndex = cand.TextIndex
+	d.Start = cand.Start - s.prefix
+	d.End = cand.End
+	return d
+}
+
+// registrableLabel 返回可注册域名中公共后缀之前的标签，例如 example.co.uk 返回 example
+func registrableLabel(d *domainutil.Domain) string {
+	return strings.TrimSuffix(d.Registrable, "."+d.PublicSuffix)
+}
+
+// mixedDigits 统计与字母相邻的数字个数，例如 examp1e 为1，OCR把字母识别成数字时常出现这种拼写
+func mixedDigits(label string) int {
+	isLetter := func(i int) bool {
+		return i >= 0 && i < len(label) && label[i] >= 'a' && label[i] <= 'z'
+	}
+	count := 0
+	for i := 0; i < len(label); i++ {
+		if label[i] >= '0' && label[i] <= '9' && (isLetter(i-1) || isLetter(i+1)) {
+			count++
+		}
+	}
+	return count
+}
+
+// labelAt 返回原始片段中与 d 的可注册标签位置相同的标签
+// 混淆字符替换不会改变点号，因此可以按从右往左的位置对应
+func labelAt(original string, d *domainutil.Domain) string {
+	labels := strings.Split(original, ".")
+	idx := len(labels) - strings.Count(d.PublicSuffix, ".") - 2
+	if idx < 0 || idx >= len(labels) {
+		return ""
+	}
+	return labels[idx]
+}
+
+// normalizeDot 将OCR识别出的全角、半角句号替换为点号
+func normalizeDot(r rune) rune {
+	switch r {
+	case '。', '．', '｡':
+		return '.'
+	}
+	return r
+}
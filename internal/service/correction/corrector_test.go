@@ -0,0 +1,82 @@
+package correction
+
+import (
+	"context"
+	"domain-analyzer/config"
+	"domain-analyzer/internal/pkg/domainutil"
+	"testing"
+)
+
+func TestRankOrdering(t *testing.T) {
+	c := &Corrector{dict: newDictionary("best\ndeals\n")}
+	ranked := c.rank([]variant{
+		{text: "bestdea1s.com", cost: 1},
+		{text: "bestxyz.com", cost: 1},
+		{text: "bestdeals.com", cost: 2},
+		{text: "bestdeals.com", cost: 1},
+	}, 60)
+
+	var got []string
+	for _, s := range ranked {
+		got = append(got, s.domain.Host)
+	}
+	// bestdea1s 仍有混淆数字被丢弃；编辑代价高的拼写即使单词覆盖率更高也排在后面
+	want := []string{"bestdeals.com", "bestxyz.com", "bestdeals.com"}
+	if len(got) != len(want) {
+		t.Fatalf("rank() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("rank() = %v, want %v", got, want)
+		}
+	}
+	if ranked[0].cost != 1 {
+		t.Errorf("rank() first cost = %v, want 1", ranked[0].cost)
+	}
+}
+
+func TestRankConfidenceWeight(t *testing.T) {
+	c := &Corrector{dict: newDictionary("best\ndeals\n")}
+	v := []variant{{text: "bestdeals.com", cost: 1}}
+	low, high := c.rank(v, 30), c.rank(v, 90)
+	if low[0].score <= high[0].score {
+		t.Errorf("score at confidence 30 = %v, should be higher than at 90 = %v", low[0].score, high[0].score)
+	}
+}
+
+func TestCorrectWithoutVerifier(t *testing.T) {
+	c := NewCorrector(&config.Config{}, nil)
+	tests := []struct {
+		text       string
+		confidence float64
+		want       string
+	}{
+		{text: "出售 bestdeals.corn", confidence: 60, want: "bestdeals.com"},
+		{text: "出售 best–deals.com", confidence: 60, want: "best-deals.com"},
+		{text: "出售 bestdeals.corn", confidence: 98, want: ""},
+		{text: "出售 g00gle.corn", confidence: 60, want: ""},
+	}
+	for _, tt := range tests {
+		domains, report := domainutil.ExtractDomainsWithReport([]string{tt.text})
+		results := c.Correct(context.Background(), domains, report, []float64{tt.confidence}, nil)
+
+		got := ""
+		for _, d := range results {
+			if d.CorrectedFrom != "" {
+				got = d.Host
+			}
+		}
+		if got != tt.want {
+			t.Errorf("Correct(%q, %v) corrected to %q, want %q", tt.text, tt.confidence, got, tt.want)
+		}
+	}
+}
+
+func TestCorrectSkipsExactText(t *testing.T) {
+	c := NewCorrector(&config.Config{}, nil)
+	domains, report := domainutil.ExtractDomainsWithReport([]string{"bestdeals.corn"})
+	results := c.Correct(context.Background(), domains, report, []float64{60}, []bool{true})
+	if len(results) != 0 {
+		t.Errorf("Correct() on exact text = %d domains, want 0", len(results))
+	}
+}
@@ -0,0 +1,58 @@
+package correction
+
+import (
+	"bufio"
+	_ "embed"
+	"strings"
+)
+
+// words.txt 是域名中常见的英文单词，每行一个，用于判断一个拼写是否“像单词”
+//
+//go:embed words.txt
+var embeddedWords string
+
+// dictionary 常用词词典
+type dictionary struct {
+	words     map[string]bool
+	maxLength int
+}
+
+func newDictionary(content string) *dictionary {
+	d := &dictionary{words: make(map[string]bool)}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" {
+			continue
+		}
+		d.words[word] = true
+		if len(word) > d.maxLength {
+			d.maxLength = len(word)
+		}
+	}
+	return d
+}
+
+// score 返回标签能被词典中的单词覆盖的比例，取值 0~1
+// 例如 bestdeals 可以拆分为 best+deals，得分为1；b3stdeals 只有 deals 被覆盖，得分约为0.56
+// 连字符视为已覆盖
+func (d *dictionary) score(label string) float64 {
+	if label == "" {
+		return 0
+	}
+
+	// covered[i] 表示 label[:i] 最多能被覆盖的字符数
+	covered := make([]int, len(label)+1)
+	for i := 1; i <= len(label); i++ {
+		covered[i] = covered[i-1]
+		if label[i-1] == '-' {
+			covered[i] = covered[i-1] + 1
+		}
+		for j := i - 2; j >= 0 && i-j <= d.maxLength; j-- {
+			if d.words[label[j:i]] && covered[j]+(i-j) > covered[i] {
+				covered[i] = covered[j] + (i - j)
+			}
+		}
+	}
+	return float64(covered[len(label)]) / float64(len(label))
+}
@@ -0,0 +1,26 @@
+package correction
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDictionaryScore(t *testing.T) {
+	d := newDictionary("best\ndeals\nshop\n")
+	tests := []struct {
+		label string
+		want  float64
+	}{
+		{label: "bestdeals", want: 1},
+		{label: "best-deals", want: 1},
+		{label: "b3stdeals", want: 5.0 / 9},
+		{label: "shopxyz", want: 4.0 / 7},
+		{label: "xyz", want: 0},
+		{label: "", want: 0},
+	}
+	for _, tt := range tests {
+		if got := d.score(tt.label); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("score(%q) = %v, want %v", tt.label, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package correction
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// confusables OCR容易混淆的字符，from 为识别结果，to 为可能的原始字符
+var confusables = []struct {
+	from string
+	to   []string
+}{
+	{"0", []string{"o"}},
+	{"o", []string{"0"}},
+	{"1", []string{"l", "i"}},
+	{"l", []string{"1", "i"}},
+	{"i", []string{"l", "1"}},
+	{"rn", []string{"m"}},
+	{"m", []string{"rn"}},
+	{"5", []string{"s"}},
+	{"s", []string{"5"}},
+	{"vv", []string{"w"}},
+	{"w", []string{"vv"}},
+}
+
+// hyphenLookalikes OCR常把连字符识别成的字符，或者直接识别成空格
+const hyphenLookalikes = "_–—‐‑− "
+
+// variant 一个候选拼写
+type variant struct {
+	text   string
+	cost   float64 // 编辑代价，替换一个混淆字符记为1
+	prefix int     // 在候选片段前面补上的字符数，用于修正域名在文本中的起始位置
+}
+
+// substitutionVariants 通过替换混淆字符生成候选拼写，最多替换 maxEdits 处
+// 返回结果不包含原始拼写，按生成顺序排列且不重复
+func substitutionVariants(text string, maxEdits, limit int) []variant {
+	seen := map[string]bool{text: true}
+	var results []variant
+
+	current := []variant{{text: text}}
+	for edit := 1; edit <= maxEdits && len(results) < limit; edit++ {
+		var next []variant
+		for _, v := range current {
+			for i := 0; i < len(v.text); i++ {
+				for _, c := range confusables {
+					if !strings.HasPrefix(v.text[i:], c.from) {
+						continue
+					}
+					for _, to := range c.to {
+						s := v.text[:i] + to + v.text[i+len(c.from):]
+						if seen[s] {
+							continue
+						}
+						seen[s] = true
+						nv := variant{text: s, cost: float64(edit)}
+						next = append(next, nv)
+						results = append(results, nv)
+						if len(results) >= limit {
+							return results
+						}
+					}
+				}
+			}
+		}
+		current = next
+	}
+	return results
+}
+
+// hyphenVariant 检查候选片段前面是否紧跟“被识别错的连字符”和另一段标签，
+// 例如 "best–deals.com" 或 "best deals.com" 中的 "deals.com"，返回补上连字符后的拼写
+// 被误识别的字符不是空格时几乎可以确定原本是一个域名，编辑代价较低
+func hyphenVariant(text string, start int, candidate string) (variant, bool) {
+	runes := []rune(text)
+	if start < 2 || start > len(runes) {
+		return variant{}, false
+	}
+
+	sep := runes[start-1]
+	if !strings.ContainsRune(hyphenLookalikes, sep) {
+		return variant{}, false
+	}
+
+	// 向前查找前一段标签
+	j := start - 1
+	for j > 0 && isLabelRune(runes[j-1]) {
+		j--
+	}
+	if j == start-1 {
+		return variant{}, false
+	}
+
+	cost := 1.0
+	if sep != ' ' {
+		cost = 0.5
+	}
+	return variant{
+		text:   strings.ToLower(string(runes[j:start-1])) + "-" + candidate,
+		cost:   cost,
+		prefix: start - j,
+	}, true
+}
+
+// isLabelRune 判断是否为ASCII域名标签中允许的字符
+func isLabelRune(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-')
+}
@@ -0,0 +1,91 @@
+package correction
+
+import "testing"
+
+func TestSubstitutionVariants(t *testing.T) {
+	tests := []struct {
+		text     string
+		maxEdits int
+		want     string
+		cost     float64
+	}{
+		{text: "examp1e.com", maxEdits: 1, want: "example.com", cost: 1},
+		{text: "g00gle.com", maxEdits: 2, want: "google.com", cost: 2},
+		{text: "bestdeals.corn", maxEdits: 1, want: "bestdeals.com", cost: 1},
+		{text: "vvorld.com", maxEdits: 1, want: "world.com", cost: 1},
+	}
+	for _, tt := range tests {
+		variants := substitutionVariants(tt.text, tt.maxEdits, 1000)
+		seen := make(map[string]bool)
+		found := false
+		for _, v := range variants {
+			if v.text == tt.text {
+				t.Errorf("substitutionVariants(%q) contains the original text", tt.text)
+			}
+			if seen[v.text] {
+				t.Errorf("substitutionVariants(%q) contains duplicate %q", tt.text, v.text)
+			}
+			seen[v.text] = true
+			if v.text == tt.want {
+				found = true
+				if v.cost != tt.cost {
+					t.Errorf("substitutionVariants(%q) cost of %q = %v, want %v", tt.text, tt.want, v.cost, tt.cost)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("substitutionVariants(%q, %d) does not contain %q", tt.text, tt.maxEdits, tt.want)
+		}
+	}
+}
+
+func TestSubstitutionVariantsLimit(t *testing.T) {
+	if got := substitutionVariants("l1l1l1l1.com", 2, 5); len(got) != 5 {
+		t.Errorf("substitutionVariants with limit 5 returned %d variants", len(got))
+	}
+	if got := substitutionVariants("g00gle.com", 1, 100); containsVariant(got, "google.com") {
+		t.Errorf("substitutionVariants with maxEdits 1 should not replace two characters")
+	}
+}
+
+func TestHyphenVariant(t *testing.T) {
+	tests := []struct {
+		text   string
+		start  int
+		ok     bool
+		want   string
+		cost   float64
+		prefix int
+	}{
+		{text: "best–deals.com", start: 5, ok: true, want: "best-deals.com", cost: 0.5, prefix: 5},
+		{text: "best_deals.com", start: 5, ok: true, want: "best-deals.com", cost: 0.5, prefix: 5},
+		{text: "出售 best deals.com", start: 8, ok: true, want: "best-deals.com", cost: 1, prefix: 5},
+		{text: "BEST—deals.com", start: 5, ok: true, want: "best-deals.com", cost: 0.5, prefix: 5},
+		{text: "出售 deals.com", start: 3, ok: false},
+		{text: "best.deals.com", start: 5, ok: false},
+		{text: "deals.com", start: 0, ok: false},
+	}
+	for _, tt := range tests {
+		candidate := string([]rune(tt.text)[tt.start:])
+		v, ok := hyphenVariant(tt.text, tt.start, candidate)
+		if ok != tt.ok {
+			t.Errorf("hyphenVariant(%q, %d) ok = %v, want %v", tt.text, tt.start, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if v.text != tt.want || v.cost != tt.cost || v.prefix != tt.prefix {
+			t.Errorf("hyphenVariant(%q, %d) = %+v, want {text:%s cost:%v prefix:%d}", tt.text, tt.start, v, tt.want, tt.cost, tt.prefix)
+		}
+	}
+}
+
+func containsVariant(variants []variant, text string) bool {
+	for _, v := range variants {
+		if v.text == text {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package correction
+
+import (
+	"context"
+	"domain-analyzer/internal/pkg/domainutil"
+	"domain-analyzer/internal/service/domain"
+	"errors"
+)
+
+// webArchiveVerifier 以是否被 Web Archive 收录过判断域名是否真实存在过
+// 过期域名通常已经无法解析，因此不使用DNS验证
+type webArchiveVerifier struct {
+	webArchive domain.WebArchive
+}
+
+// NewWebArchiveVerifier 创建基于 Web Archive 的验证器
+func NewWebArchiveVerifier(webArchive domain.WebArchive) Verifier {
+	return &webArchiveVerifier{webArchive: webArchive}
+}
+
+// Exists 实现 Verifier 接口
+func (v *webArchiveVerifier) Exists(ctx context.Context, d *domainutil.Domain) (bool, error) {
+	_, err := v.webArchive.RecognizeDomains(ctx, d.URL())
+	if errors.Is(err, domain.ErrNoArchive) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
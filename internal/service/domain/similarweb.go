@@ -2,8 +2,10 @@ package domain
 
 import (
 	"context"
+	"domain-analyzer/config"
 	"domain-analyzer/internal/model"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,8 +16,16 @@ import (
 
 const (
 	similarWebBaseURL = "https://api.similarweb.com/v1/website"
+
+	// 默认流量查询参数
+	defaultTrafficGranularity = "monthly"
+	defaultTrafficCountry     = "world"
+	defaultTrafficMonths      = 3
 )
 
+// ErrNoTrafficData 表示 SimilarWeb 没有该域名的流量数据（HTTP 404），属于正常的查询结果
+var ErrNoTrafficData = errors.New("no traffic data found")
+
 var (
 	similarWebInstance SimilarWeb
 	similarWebOnce     sync.Once
@@ -41,9 +51,38 @@ type TrafficQuery struct {
 	Country string `json:"country"`
 }
 
+// DefaultTrafficQuery 根据配置生成默认的流量查询参数
+// 日期窗口以 now 为基准，取最近 Months 个完整月份（不含当月）
+func DefaultTrafficQuery(config *config.Config, now time.Time) TrafficQuery {
+	query := TrafficQuery{
+		Granularity:    config.SimilarWeb.Granularity,
+		MainDomainOnly: config.SimilarWeb.MainDomainOnly,
+		ShowVerified:   config.SimilarWeb.ShowVerified,
+		Format:         "json",
+		Country:        config.SimilarWeb.Country,
+	}
+	if query.Granularity == "" {
+		query.Granularity = defaultTrafficGranularity
+	}
+	if query.Country == "" {
+		query.Country = defaultTrafficCountry
+	}
+
+	months := config.SimilarWeb.Months
+	if months <= 0 {
+		months = defaultTrafficMonths
+	}
+	lastMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, -1, 0)
+	query.EndDate = lastMonth.Format("2006-01")
+	query.StartDate = lastMonth.AddDate(0, -(months - 1), 0).Format("2006-01")
+
+	return query
+}
+
 type SimilarWeb interface {
 	// TotalTrafficAndEngagement 获取某个域名的总流量
 	// https://developers.similarweb.com/reference/visits
+	// 没有该域名的流量数据时返回 ErrNoTrafficData
 	TotalTrafficAndEngagement(ctx context.Context, query TrafficQuery, domain *url.URL) (model.TotalTrafficAndEngagementResp, error)
 }
 
@@ -140,6 +179,10 @@ func (c *similarWebClient) TotalTrafficAndEngagement(ctx context.Context, query
 	}
 
 	// 检查响应状态
+	if resp.StatusCode == http.StatusNotFound {
+		return model.TotalTrafficAndEngagementResp{}, fmt.Errorf("%w for domain: %s (Response: %s)",
+			ErrNoTrafficData, domain.Host, string(body))
+	}
 	if resp.StatusCode != http.StatusOK {
 		return model.TotalTrafficAndEngagementResp{}, fmt.Errorf("API request failed with status %d (URL: %s, Response: %s)",
 			resp.StatusCode, requestURL, string(body))
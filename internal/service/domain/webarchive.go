@@ -1,10 +1,12 @@
 package domain
 
 import (
+	"bytes"
 	"context"
 	"domain-analyzer/config"
 	"domain-analyzer/internal/model"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -17,6 +19,9 @@ const (
 	webArchiveBaseURL = "https://web.archive.org/cdx/search/cdx"
 )
 
+// ErrNoArchive 表示域名从未被 Web Archive 收录，属于正常的查询结果
+var ErrNoArchive = errors.New("no archive found")
+
 var (
 	instance WebArchive
 	once     sync.Once
@@ -26,6 +31,7 @@ var (
 // WebArchive 定义了与 Internet Archive 交互的接口
 type WebArchive interface {
 	// RecognizeDomains 从 Web Archive 服务获取某个域名最早的收录时间
+	// 域名从未被收录时返回 ErrNoArchive
 	RecognizeDomains(ctx context.Context, domain *url.URL) (model.WebArchiveResponse, error)
 }
 
@@ -66,7 +72,7 @@ func GetWebArchive() WebArchive {
 		if cfg != nil {
 			proxyURL = cfg.WebArchive.ProxyURL
 		}
-		instance = newCDXClient(proxyURL)
+		instance = &memoWebArchive{next: newCDXClient(proxyURL)}
 	})
 	return instance
 }
@@ -107,6 +113,16 @@ func (c *cdxClient) RecognizeDomains(ctx context.Context, domain *url.URL) (mode
 		return model.WebArchiveResponse{}, fmt.Errorf("read response failed: %w", err)
 	}
 
+	// 检查响应状态
+	if resp.StatusCode != http.StatusOK {
+		return model.WebArchiveResponse{}, fmt.Errorf("CDX request failed with status %d", resp.StatusCode)
+	}
+
+	// 没有任何收录记录时CDX可能返回空响应体
+	if len(bytes.TrimSpace(body)) == 0 {
+		return model.WebArchiveResponse{}, fmt.Errorf("%w for domain: %s", ErrNoArchive, domain.Host)
+	}
+
 	// 解析响应
 	var cdxResp CDXResponse
 	if err := json.Unmarshal(body, &cdxResp); err != nil {
@@ -115,7 +131,11 @@ func (c *cdxClient) RecognizeDomains(ctx context.Context, domain *url.URL) (mode
 
 	// 检查响应格式
 	if len(cdxResp) < 2 {
-		return model.WebArchiveResponse{}, fmt.Errorf("no archive found for domain: %s", domain.Host)
+		return model.WebArchiveResponse{}, fmt.Errorf("%w for domain: %s", ErrNoArchive, domain.Host)
+	}
+
+	if len(cdxResp[1]) < 2 {
+		return model.WebArchiveResponse{}, fmt.Errorf("unexpected CDX record: %v", cdxResp[1])
 	}
 
 	// 解析时间戳
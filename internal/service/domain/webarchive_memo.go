@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"context"
+	"domain-analyzer/internal/model"
+	"errors"
+	"net/url"
+	"sync"
+)
+
+type archiveMemoKey struct{}
+
+// archiveMemo 记录一次请求内每个域名的 Web Archive 查询结果，查询出错时不记录
+type archiveMemo struct {
+	mu    sync.Mutex
+	calls map[string]*archiveCall
+}
+
+// archiveCall 一次进行中或已完成的查询，同一域名的并发查询等待同一个结果
+type archiveCall struct {
+	done     chan struct{}
+	response model.WebArchiveResponse
+	err      error
+}
+
+// WithArchiveMemo 返回在本次请求内复用 Web Archive 查询结果的ctx
+// OCR纠错验证候选拼写和分析域名都会查询收录情况，同一域名只请求一次CDX
+func WithArchiveMemo(ctx context.Context) context.Context {
+	return context.WithValue(ctx, archiveMemoKey{}, &archiveMemo{calls: make(map[string]*archiveCall)})
+}
+
+// memoWebArchive 在ctx带有 archiveMemo 时复用查询结果，否则直接查询
+type memoWebArchive struct {
+	next WebArchive
+}
+
+// RecognizeDomains 实现 WebArchive 接口
+func (m *memoWebArchive) RecognizeDomains(ctx context.Context, domain *url.URL) (model.WebArchiveResponse, error) {
+	memo, ok := ctx.Value(archiveMemoKey{}).(*archiveMemo)
+	if !ok {
+		return m.next.RecognizeDomains(ctx, domain)
+	}
+
+	for {
+		memo.mu.Lock()
+		call, found := memo.calls[domain.Host]
+		if !found {
+			call = &archiveCall{done: make(chan struct{})}
+			memo.calls[domain.Host] = call
+		}
+		memo.mu.Unlock()
+
+		if !found {
+			call.response, call.err = m.next.RecognizeDomains(ctx, domain)
+			// 只复用确定的查询结果，网络错误、超时等不记录，之后的查询重新请求
+			if call.err != nil && !errors.Is(call.err, ErrNoArchive) {
+				memo.mu.Lock()
+				delete(memo.calls, domain.Host)
+				memo.mu.Unlock()
+			}
+			close(call.done)
+			return call.response, call.err
+		}
+
+		select {
+		case <-call.done:
+			if call.err == nil || errors.Is(call.err, ErrNoArchive) {
+				return call.response, call.err
+			}
+			// 正在进行的查询失败了，重新查询
+		case <-ctx.Done():
+			return model.WebArchiveResponse{}, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package ocr
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"domain-analyzer/config"
+	"domain-analyzer/internal/model"
+	"domain-analyzer/internal/pkg/logger"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// 默认的缓存参数
+const (
+	defaultCacheTTL        = 7 * 24 * time.Hour
+	defaultCacheMaxEntries = 1000
+	// emptyCacheTTL 没有识别出域名的结果可能来自暂时出错后的降级，只短暂缓存
+	emptyCacheTTL = time.Hour
+)
+
+// Cache 定义OCR结果缓存的接口，key 为图片内容及调用参数的哈希
+type Cache interface {
+	// Get 返回未过期的缓存结果，不存在时返回 nil, nil
+	Get(ctx context.Context, key string) (*OCRResponse, error)
+	Set(ctx context.Context, key string, resp *OCRResponse, ttl time.Duration) error
+}
+
+type cacheBypassKey struct{}
+
+// WithCacheBypass 返回不读取缓存的context，识别结果仍会写入缓存
+func WithCacheBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheBypassKey{}, true)
+}
+
+// cacheBypassed 判断context是否要求不读取缓存
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(cacheBypassKey{}).(bool)
+	return bypass
+}
+
+// cachedOCR 按图片内容缓存OCR结果，同一张截图重复上传时不再调用OCR服务
+// 按顺序查询各级缓存，命中后回填到前面的缓存
+type cachedOCR struct {
+	service  OCRService
+	caches   []Cache
+	ttl      time.Duration
+	emptyTTL time.Duration
+	settings string // OCR服务链及识别参数，配置变化后不再使用之前的缓存
+}
+
+// NewCachedOCR 为OCR服务添加缓存，caches 按从快到慢的顺序排列，例如内存缓存、数据库缓存
+// 缓存键包含图片内容和OCR相关的配置，未配置有效期时使用默认值
+func NewCachedOCR(config *config.Config, service OCRService, caches ...Cache) OCRService {
+	c := &cachedOCR{
+		service:  service,
+		caches:   caches,
+		ttl:      time.Duration(config.OCR.Cache.TTLHours) * time.Hour,
+		emptyTTL: emptyCacheTTL,
+		settings: ocrSettings(config),
+	}
+	if c.ttl <= 0 {
+		c.ttl = defaultCacheTTL
+	}
+	if c.emptyTTL > c.ttl {
+		c.emptyTTL = c.ttl
+	}
+	return c
+}
+
+// ocrSettings 返回影响识别结果的OCR配置，不包含密钥、超时等
+func ocrSettings(config *config.Config) string {
+	ocrConfig := config.OCR
+	data, _ := json.Marshal(struct {
+		Providers          []string
+		Tencent            interface{}
+		TesseractLanguages string
+		TesseractPSM       int
+		HTTPURL            string
+		Tiling             interface{}
+		Preprocess         interface{}
+	}{
+		Providers:          providerNames(config),
+		Tencent:            ocrConfig.Tencent,
+		TesseractLanguages: ocrConfig.Tesseract.Languages,
+		TesseractPSM:       ocrConfig.Tesseract.PSM,
+		HTTPURL:            ocrConfig.HTTP.URL,
+		Tiling:             ocrConfig.Tiling,
+		Preprocess:         ocrConfig.Preprocess,
+	})
+	return string(data)
+}
+
+// Recognize 实现OCRService接口，命中缓存时返回结果的 Cached 为true
+func (c *cachedOCR) Recognize(ctx context.Context, imageBytes []byte) (*OCRResponse, error) {
+	key := c.cacheKey(ctx, imageBytes)
+
+	if !cacheBypassed(ctx) {
+		for i, cache := range c.caches {
+			resp, err := cache.Get(ctx, key)
+			if err != nil {
+				logger.Warnf("get ocr cache failed: %v", err)
+				continue
+			}
+			if resp == nil {
+				continue
+			}
+			c.store(ctx, c.caches[:i], key, resp)
+			hit := *resp
+			hit.Cached = true
+			return &hit, nil
+		}
+	}
+
+	resp, err := c.service.Recognize(ctx, imageBytes)
+	if err != nil {
+		return nil, err
+	}
+	c.store(ctx, c.caches, key, resp)
+	return resp, nil
+}
+
+// store 将结果写入缓存，没有识别出域名的结果使用较短的有效期，写入失败只记录日志
+func (c *cachedOCR) store(ctx context.Context, caches []Cache, key string, resp *OCRResponse) {
+	ttl := c.ttl
+	if !hasDomains(resp) {
+		ttl = c.emptyTTL
+	}
+	for _, cache := range caches {
+		if err := cache.Set(ctx, key, resp, ttl); err != nil {
+			logger.Warnf("set ocr cache failed: %v", err)
+		}
+	}
+}
+
+// cacheKey 返回图片内容和OCR配置的SHA-256，请求覆盖了OCR调用参数时参数也参与计算
+func (c *cachedOCR) cacheKey(ctx context.Context, imageBytes []byte) string {
+	hash := sha256.New()
+	hash.Write(imageBytes)
+	hash.Write([]byte{0})
+	hash.Write([]byte(c.settings))
+	if options, ok := tencentOptionsFrom(ctx); ok {
+		hash.Write([]byte{0})
+		hash.Write([]byte(options.key()))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// memoryCache 进程内的LRU缓存，保存和返回的都是结果的副本
+type memoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List // 最近使用的在前
+}
+
+type memoryEntry struct {
+	key       string
+	resp      *OCRResponse
+	expiresAt time.Time
+}
+
+// NewMemoryCache 创建最多保存 maxEntries 条结果的内存缓存，maxEntries 为0时使用默认值
+func NewMemoryCache(maxEntries int) Cache {
+	if maxEntries <= 0 {
+		maxEntries = defaultCacheMaxEntries
+	}
+	return &memoryCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Get 实现 Cache 接口
+func (m *memoryCache) Get(_ context.Context, key string) (*OCRResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, ok := m.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	entry := elem.Value.(*memoryEntry)
+	if time.Now().After(entry.expiresAt) {
+		m.order.Remove(elem)
+		delete(m.entries, key)
+		return nil, nil
+	}
+	m.order.MoveToFront(elem)
+	return cloneResponse(entry.resp), nil
+}
+
+// Set 实现 Cache 接口，超出容量时淘汰最久未使用的结果
+func (m *memoryCache) Set(_ context.Context, key string, resp *OCRResponse, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := &memoryEntry{key: key, resp: cloneResponse(resp), expiresAt: time.Now().Add(ttl)}
+	if elem, ok := m.entries[key]; ok {
+		elem.Value = entry
+		m.order.MoveToFront(elem)
+		return nil
+	}
+	m.entries[key] = m.order.PushFront(entry)
+	for m.order.Len() > m.maxEntries {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryEntry).key)
+	}
+	return nil
+}
+
+// cloneResponse 深拷贝识别结果，内存缓存中的结果与调用方互不影响
+func cloneResponse(resp *OCRResponse) *OCRResponse {
+	clone := *resp
+	clone.Preprocessing = append([]string(nil), resp.Preprocessing...)
+	clone.Detections = make([]Detection, len(resp.Detections))
+	for i, d := range resp.Detections {
+		d.Polygon = append([]model.Point(nil), d.Polygon...)
+		clone.Detections[i] = d
+	}
+	return &clone
+}
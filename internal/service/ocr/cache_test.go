@@ -0,0 +1,148 @@
+package ocr
+
+import (
+	"context"
+	"domain-analyzer/config"
+	"domain-analyzer/internal/model"
+	"testing"
+	"time"
+)
+
+func response(text string) *OCRResponse {
+	return &OCRResponse{
+		Provider:      "test",
+		Preprocessing: []string{"grayscale"},
+		Detections: []Detection{{
+			Text:    text,
+			Polygon: []model.Point{{X: 1, Y: 2}, {X: 3, Y: 2}, {X: 3, Y: 4}, {X: 1, Y: 4}},
+		}},
+	}
+}
+
+func TestMemoryCacheLRU(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(2)
+	cache.Set(ctx, "a", response("a.com"), time.Hour)
+	cache.Set(ctx, "b", response("b.com"), time.Hour)
+	// 读取 a 后 b 成为最久未使用的结果
+	if resp, _ := cache.Get(ctx, "a"); resp == nil {
+		t.Fatalf("Get(a) = nil")
+	}
+	cache.Set(ctx, "c", response("c.com"), time.Hour)
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if resp, _ := cache.Get(ctx, key); (resp != nil) != want {
+			t.Errorf("Get(%s) found = %v, want %v", key, resp != nil, want)
+		}
+	}
+}
+
+func TestMemoryCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(10)
+	cache.Set(ctx, "expired", response("a.com"), -time.Second)
+	cache.Set(ctx, "valid", response("b.com"), time.Hour)
+	if resp, _ := cache.Get(ctx, "expired"); resp != nil {
+		t.Errorf("Get(expired) = %+v, want nil", resp)
+	}
+	if resp, _ := cache.Get(ctx, "valid"); resp == nil {
+		t.Errorf("Get(valid) = nil")
+	}
+}
+
+func TestMemoryCacheCopies(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(10)
+	resp := response("a.com")
+	cache.Set(ctx, "a", resp, time.Hour)
+
+	// 修改写入的结果和读取到的结果都不影响缓存
+	resp.Detections[0].Text = "changed"
+	resp.Detections[0].Polygon[0].Y += 100
+	got, _ := cache.Get(ctx, "a")
+	got.Detections[0].Polygon[1].Y += 100
+	got.Preprocessing[0] = "changed"
+
+	again, _ := cache.Get(ctx, "a")
+	if again.Detections[0].Text != "a.com" || again.Detections[0].Polygon[0].Y != 2 || again.Detections[0].Polygon[1].Y != 2 ||
+		again.Preprocessing[0] != "grayscale" {
+		t.Errorf("cached response was modified: %+v", again)
+	}
+}
+
+// ttlRecorder 记录写入缓存时使用的有效期
+type ttlRecorder struct {
+	ttls []time.Duration
+}
+
+func (r *ttlRecorder) Get(context.Context, string) (*OCRResponse, error) { return nil, nil }
+
+func (r *ttlRecorder) Set(_ context.Context, _ string, _ *OCRResponse, ttl time.Duration) error {
+	r.ttls = append(r.ttls, ttl)
+	return nil
+}
+
+// fixedOCR 返回固定的识别结果，记录调用次数
+type fixedOCR struct {
+	resp  *OCRResponse
+	calls int
+}
+
+func (f *fixedOCR) Recognize(context.Context, []byte) (*OCRResponse, error) {
+	f.calls++
+	return cloneResponse(f.resp), nil
+}
+
+func TestCachedOCRTTL(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.OCR.Cache.TTLHours = 24
+	recorder := &ttlRecorder{}
+
+	NewCachedOCR(cfg, &fixedOCR{resp: response("出售 example.com")}, recorder).Recognize(context.Background(), []byte("a"))
+	NewCachedOCR(cfg, &fixedOCR{resp: response("没有域名")}, recorder).Recognize(context.Background(), []byte("b"))
+	if len(recorder.ttls) != 2 || recorder.ttls[0] != 24*time.Hour || recorder.ttls[1] != emptyCacheTTL {
+		t.Errorf("cache ttls = %v, want [24h %v]", recorder.ttls, emptyCacheTTL)
+	}
+}
+
+func TestCachedOCRHit(t *testing.T) {
+	service := &fixedOCR{resp: response("example.com")}
+	c := NewCachedOCR(&config.Config{}, service, NewMemoryCache(10))
+	ctx := context.Background()
+
+	first, _ := c.Recognize(ctx, []byte("image"))
+	second, _ := c.Recognize(ctx, []byte("image"))
+	if service.calls != 1 || first.Cached || !second.Cached {
+		t.Errorf("calls = %d, cached = %v/%v, want 1 call and the second result cached", service.calls, first.Cached, second.Cached)
+	}
+	c.Recognize(WithCacheBypass(ctx), []byte("image"))
+	if service.calls != 2 {
+		t.Errorf("calls with cache bypass = %d, want 2", service.calls)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	ctx := context.Background()
+	base := NewCachedOCR(&config.Config{}, nil).(*cachedOCR)
+
+	other := &config.Config{}
+	other.OCR.Providers = []string{"tesseract"}
+	otherProviders := NewCachedOCR(other, nil).(*cachedOCR)
+
+	key := base.cacheKey(ctx, []byte("image"))
+	tests := []struct {
+		name string
+		key  string
+		same bool
+	}{
+		{name: "same image", key: base.cacheKey(ctx, []byte("image")), same: true},
+		{name: "different image", key: base.cacheKey(ctx, []byte("other"))},
+		{name: "different providers", key: otherProviders.cacheKey(ctx, []byte("image"))},
+		{name: "tencent options", key: base.cacheKey(WithTencentOptions(ctx, TencentOptions{Action: TencentActionAccurate}), []byte("image"))},
+	}
+	for _, tt := range tests {
+		if (tt.key == key) != tt.same {
+			t.Errorf("%s: key equal = %v, want %v", tt.name, tt.key == key, tt.same)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package ocr
+
+import (
+	"context"
+	"domain-analyzer/internal/pkg/errors"
+	"domain-analyzer/internal/pkg/logger"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Provider 表示OCR链中一个具名的OCR服务
+type Provider struct {
+	Name    string
+	Service OCRService
+}
+
+// AcceptFunc 判断OCR结果是否可用，不可用时继续尝试下一个服务
+type AcceptFunc func(resp *OCRResponse) bool
+
+// ChainOCR 按顺序尝试多个OCR服务，直到某个服务的结果可用
+// 服务出错、超时或结果不可用时尝试下一个；都不可用时返回第一个成功的结果，都出错时返回最后一个错误
+// 超时由各服务自己的 withTimeout 控制，长截图切图识别时每张小图分别计时
+type ChainOCR struct {
+	providers []Provider
+	accept    AcceptFunc
+}
+
+// NewChainOCR 创建OCR服务链，accept 为nil时只要识别成功即可用
+func NewChainOCR(providers []Provider, accept AcceptFunc) *ChainOCR {
+	return &ChainOCR{
+		providers: providers,
+		accept:    accept,
+	}
+}
+
+// Recognize 实现OCRService接口，返回结果的 Provider 为实际产生结果的服务名称
+func (c *ChainOCR) Recognize(ctx context.Context, imageBytes []byte) (*OCRResponse, error) {
+	var fallback *OCRResponse
+	var lastErr error
+
+	for _, p := range c.providers {
+		resp, err := p.Service.Recognize(ctx, imageBytes)
+		if err != nil {
+			// 本地校验发现图片有问题或请求已取消时，换一个服务也没有意义
+			// OCR服务自己报告的图片错误（例如超出该服务的大小限制）仍然尝试下一个服务
+			if isRejectedImage(err) || ctx.Err() != nil {
+				return nil, err
+			}
+			logger.Warnf("ocr provider %s failed: %v", p.Name, err)
+			lastErr = err
+			continue
+		}
+
+		resp.Provider = p.Name
+		if c.accept == nil || c.accept(resp) {
+			return resp, nil
+		}
+		logger.Infof("ocr provider %s found no domains in %d lines, trying next provider", p.Name, len(resp.Detections))
+		if fallback == nil {
+			fallback = resp
+		}
+	}
+
+	if fallback != nil {
+		return fallback, nil
+	}
+	if lastErr == nil {
+		return nil, fmt.Errorf("%w: no ocr provider configured", ErrServiceError)
+	}
+	return nil, lastErr
+}
+
+// timeoutOCR 限制单次调用OCR服务的时间，超时后返回504错误
+type timeoutOCR struct {
+	name    string
+	service OCRService
+	timeout time.Duration
+}
+
+// withTimeout 为OCR服务添加超时限制，timeout 为0时返回原服务
+// 各服务都通过ctx控制请求的生命周期，超时后调用会及时返回
+func withTimeout(name string, service OCRService, timeout time.Duration) OCRService {
+	if timeout <= 0 {
+		return service
+	}
+	return &timeoutOCR{name: name, service: service, timeout: timeout}
+}
+
+// Recognize 实现OCRService接口
+func (t *timeoutOCR) Recognize(ctx context.Context, imageBytes []byte) (*OCRResponse, error) {
+	callCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	resp, err := t.service.Recognize(callCtx, imageBytes)
+	if err != nil && ctx.Err() == nil && callCtx.Err() == context.DeadlineExceeded {
+		return nil, errors.NewServerError("OCR服务响应超时", fmt.Errorf("%w: %s: %v", ErrServiceError, t.name, err)).
+			WithStatus(http.StatusGatewayTimeout)
+	}
+	return resp, err
+}
@@ -8,3 +8,29 @@ var (
 	ErrInvalidImage = errors.New("invalid image format")
 	ErrServiceError = errors.New("ocr service error")
 )
+
+// rejectedImageError 标记在本地校验或解码时发现的图片问题
+// 与OCR服务返回的图片错误不同，这类错误换一个OCR服务也无法解决
+type rejectedImageError struct {
+	err error
+}
+
+func (e *rejectedImageError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap 支持错误链
+func (e *rejectedImageError) Unwrap() error {
+	return e.err
+}
+
+// rejectImage 将本地发现的图片问题标记为 rejectedImageError
+func rejectImage(err error) error {
+	return &rejectedImageError{err: err}
+}
+
+// isRejectedImage 判断错误是否为本地发现的图片问题
+func isRejectedImage(err error) bool {
+	var rejected *rejectedImageError
+	return errors.As(err, &rejected)
+}
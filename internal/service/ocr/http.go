@@ -0,0 +1,74 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"domain-analyzer/config"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPOCR 调用通用HTTP OCR接口的服务实现
+// 请求体为图片的原始内容，响应体为与 OCRResponse 相同结构的JSON，例如
+// {"detections": [{"text": "example.com", "confidence": 98, "polygon": [{"x": 0, "y": 0}, ...]}]}
+type HTTPOCR struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewHTTPOCR 创建新的HTTP OCR服务实例
+func NewHTTPOCR(config *config.Config) (*HTTPOCR, error) {
+	cfg := config.OCR.HTTP
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("ocr http url is not configured")
+	}
+	timeout := 30 * time.Second
+	if cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+	return &HTTPOCR{
+		url:     cfg.URL,
+		headers: cfg.Headers,
+		client:  &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Recognize 实现OCRService接口，识别图片中的全部文字
+func (h *HTTPOCR) Recognize(ctx context.Context, imageBytes []byte) (*OCRResponse, error) {
+	if len(imageBytes) == 0 {
+		return nil, rejectImage(ErrEmptyImage)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(imageBytes))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", http.DetectContentType(imageBytes))
+	for k, v := range h.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrServiceError, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: read response failed: %v", ErrServiceError, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected status %d: %s", ErrServiceError, resp.StatusCode, body)
+	}
+
+	var result OCRResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("%w: invalid response: %v", ErrServiceError, err)
+	}
+	return &result, nil
+}
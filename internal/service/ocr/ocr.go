@@ -2,16 +2,89 @@ package ocr
 
 import (
 	"context"
-	"net/url"
+	"domain-analyzer/config"
+	"domain-analyzer/internal/model"
+	"domain-analyzer/internal/pkg/domainutil"
+	"fmt"
+	"time"
 )
 
 // OCRService 定义OCR服务的接口
 type OCRService interface {
-	// RecognizeDomains 从图片中识别并提取合法域名
-	// 返回经过验证的合法域名URL对象列表
-	RecognizeDomains(ctx context.Context, imageBytes []byte) ([]*url.URL, error)
+	// Recognize 识别图片中的全部文字
+	// 域名的提取由调用方通过 domainutil 完成
+	Recognize(ctx context.Context, imageBytes []byte) (*OCRResponse, error)
 }
 
 type OCRResponse struct {
-	Texts []string `json:"texts"`
+	Detections []Detection `json:"detections"`
+	Provider   string      `json:"provider,omitempty"` // 产生该结果的OCR服务名称
+	// Preprocessing 调用OCR服务前对图片执行的预处理步骤，坐标已换算回原图
+	Preprocessing []string `json:"preprocessing,omitempty"`
+	// Cached 结果来自缓存，没有调用OCR服务
+	Cached bool `json:"cached,omitempty"`
+}
+
+// Detection 表示识别出的一行文本
+type Detection struct {
+	Text       string        `json:"text"`
+	Confidence float64       `json:"confidence"` // 置信度 0~100
+	Polygon    []model.Point `json:"polygon"`    // 文本行在原图中的四点坐标，顺序为左上、右上、右下、左下
+}
+
+// Texts 返回按识别顺序排列的全部文本
+func (r *OCRResponse) Texts() []string {
+	texts := make([]string, 0, len(r.Detections))
+	for _, d := range r.Detections {
+		texts = append(texts, d.Text)
+	}
+	return texts
+}
+
+// NewOCRService 根据配置按顺序创建OCR服务链，未配置时只使用腾讯云OCR
+// 前一个服务出错、超时或没有识别出任何域名时，尝试下一个服务
+func NewOCRService(config *config.Config) (OCRService, error) {
+	names := providerNames(config)
+
+	validator := NewImageValidator(config)
+	timeout := time.Duration(config.OCR.TimeoutSeconds) * time.Second
+	providers := make([]Provider, 0, len(names))
+	for _, name := range names {
+		service, err := New(name, config)
+		if err != nil {
+			return nil, err
+		}
+		if service, err = withPreprocess(service, config.OCR.Preprocess[name], validator); err != nil {
+			return nil, fmt.Errorf("ocr provider %s: %w", name, err)
+		}
+		// 超时限制在切图之内，长截图的每张小图分别计时
+		service = withTiling(withTimeout(name, service, timeout), config)
+		providers = append(providers, Provider{Name: name, Service: service})
+	}
+
+	return NewChainOCR(providers, hasDomains), nil
+}
+
+// providerNames 返回配置的OCR服务名称，未配置时只使用腾讯云OCR
+func providerNames(config *config.Config) []string {
+	if len(config.OCR.Providers) == 0 {
+		return []string{"tencent"}
+	}
+	return config.OCR.Providers
+}
+
+// UsesProvider 判断配置的OCR服务链中是否包含指定的服务
+func UsesProvider(config *config.Config, name string) bool {
+	for _, provider := range providerNames(config) {
+		if provider == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDomains 判断OCR结果中是否包含域名
+func hasDomains(resp *OCRResponse) bool {
+	domains, _ := domainutil.ExtractDomains(resp.Texts())
+	return len(domains) > 0
 }
@@ -0,0 +1,286 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"domain-analyzer/config"
+	"domain-analyzer/internal/model"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+
+	"golang.org/x/image/draw"
+)
+
+// 暗色模式反色的取值
+const (
+	invertAuto   = "auto"   // 平均亮度低于一半时反色
+	invertAlways = "always" // 总是反色
+)
+
+// maxUpscale 放大图片的最大倍数
+const maxUpscale = 4.0
+
+// maxOCRImageSide 提交给OCR服务的图片最长边，与腾讯云OCR的要求一致
+const maxOCRImageSide = 10000
+
+// preprocessedOCR 在调用OCR服务前对图片进行预处理，识别结果的坐标换算回原图坐标
+// 放大后的图片不超过图片校验的像素数和文件大小限制
+type preprocessedOCR struct {
+	service   OCRService
+	options   config.Preprocess
+	maxPixels int
+	maxBytes  int64
+}
+
+// withPreprocess 为OCR服务添加预处理，未启用任何预处理时返回原服务，反色选项无效时返回错误
+func withPreprocess(service OCRService, options config.Preprocess, validator *ImageValidator) (OCRService, error) {
+	switch options.Invert {
+	case "", invertAuto, invertAlways:
+	default:
+		return nil, fmt.Errorf("invalid preprocess invert option %q, expected %q or %q", options.Invert, invertAuto, invertAlways)
+	}
+	if !options.Grayscale && !options.Contrast && !options.Binarize && options.MinWidth <= 0 && options.Invert == "" {
+		return service, nil
+	}
+	return &preprocessedOCR{
+		service:   service,
+		options:   options,
+		maxPixels: validator.maxPixels,
+		maxBytes:  validator.maxBytes,
+	}, nil
+}
+
+// Recognize 实现OCRService接口，返回结果的 Preprocessing 为实际执行的预处理步骤
+func (p *preprocessedOCR) Recognize(ctx context.Context, imageBytes []byte) (*OCRResponse, error) {
+	if isPDF(imageBytes) {
+		return p.service.Recognize(ctx, imageBytes)
+	}
+	img, _, err := image.Decode(bytes.NewReader(imageBytes))
+	if err != nil {
+		return nil, rejectImage(fmt.Errorf("%w: %v", ErrInvalidImage, err))
+	}
+
+	processed, scale, steps := preprocessImage(img, p.options, p.maxScale(img.Bounds()))
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, processed); err != nil {
+		return nil, fmt.Errorf("%w: encode preprocessed image failed: %v", ErrServiceError, err)
+	}
+	// 放大后的文件超过大小限制时不放大
+	if scale != 1 && int64(buf.Len()) > p.maxBytes {
+		processed, scale, steps = preprocessImage(img, p.options, 1)
+		buf.Reset()
+		if err := png.Encode(&buf, processed); err != nil {
+			return nil, fmt.Errorf("%w: encode preprocessed image failed: %v", ErrServiceError, err)
+		}
+	}
+
+	resp, err := p.service.Recognize(ctx, buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	// 放大后的坐标换算回原图坐标
+	if scale != 1 {
+		for i := range resp.Detections {
+			for j, pt := range resp.Detections[i].Polygon {
+				resp.Detections[i].Polygon[j] = model.Point{
+					X: int(math.Round(float64(pt.X) / scale)),
+					Y: int(math.Round(float64(pt.Y) / scale)),
+				}
+			}
+		}
+	}
+	resp.Preprocessing = steps
+	return resp, nil
+}
+
+// maxScale 返回放大后不超过最长边和像素数限制的最大倍数
+func (p *preprocessedOCR) maxScale(bounds image.Rectangle) float64 {
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= 0 || height <= 0 {
+		return 1
+	}
+	scale := float64(maxOCRImageSide) / float64(maxInt(width, height))
+	if p.maxPixels > 0 {
+		scale = math.Min(scale, math.Sqrt(float64(p.maxPixels)/float64(width*height)))
+	}
+	return scale
+}
+
+// preprocessImage 依次执行灰度化、反色、对比度拉伸、放大和二值化，放大倍数不超过 maxScale
+// 返回处理后的图片、放大倍数以及实际执行的步骤
+func preprocessImage(img image.Image, options config.Preprocess, maxScale float64) (image.Image, float64, []string) {
+	var steps []string
+	scale := 1.0
+
+	// 反色、对比度拉伸和二值化都在灰度图上进行
+	if options.Grayscale || options.Contrast || options.Binarize || options.Invert != "" {
+		img = toGray(img)
+		steps = append(steps, "grayscale")
+	}
+
+	if gray, ok := img.(*image.Gray); ok {
+		if options.Invert == invertAlways || (options.Invert == invertAuto && meanLuminance(gray) < 128) {
+			invert(gray)
+			steps = append(steps, "invert")
+		}
+		if options.Contrast && stretchContrast(gray) {
+			steps = append(steps, "contrast")
+		}
+	}
+
+	// 小图片放大后文字更容易识别
+	if width := img.Bounds().Dx(); options.MinWidth > 0 && width < options.MinWidth {
+		if s := math.Min(math.Min(float64(options.MinWidth)/float64(width), maxUpscale), maxScale); s > 1 {
+			scale = s
+			img = upscale(img, scale)
+			steps = append(steps, fmt.Sprintf("upscale x%.2f", scale))
+		}
+	}
+
+	// 放大之后再二值化，避免插值产生的锯齿
+	if gray, ok := img.(*image.Gray); ok && options.Binarize {
+		threshold := otsuThreshold(gray)
+		binarize(gray, threshold)
+		steps = append(steps, fmt.Sprintf("binarize (otsu=%d)", threshold))
+	}
+
+	return img, scale, steps
+}
+
+// toGray 将图片转换为灰度图，坐标从 (0, 0) 开始
+func toGray(img image.Image) *image.Gray {
+	bounds := img.Bounds()
+	gray := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(gray, gray.Bounds(), img, bounds.Min, draw.Src)
+	return gray
+}
+
+// upscale 使用 Catmull-Rom 插值按比例放大图片
+func upscale(img image.Image, scale float64) image.Image {
+	bounds := img.Bounds()
+	rect := image.Rect(0, 0, int(math.Round(float64(bounds.Dx())*scale)), int(math.Round(float64(bounds.Dy())*scale)))
+	var dst draw.Image
+	if _, ok := img.(*image.Gray); ok {
+		dst = image.NewGray(rect)
+	} else {
+		dst = image.NewRGBA(rect)
+	}
+	draw.CatmullRom.Scale(dst, rect, img, bounds, draw.Src, nil)
+	return dst
+}
+
+// histogram 统计灰度图的亮度分布
+func histogram(gray *image.Gray) [256]int {
+	var hist [256]int
+	for _, v := range gray.Pix {
+		hist[v]++
+	}
+	return hist
+}
+
+// meanLuminance 计算灰度图的平均亮度
+func meanLuminance(gray *image.Gray) float64 {
+	if len(gray.Pix) == 0 {
+		return 0
+	}
+	var sum int
+	for _, v := range gray.Pix {
+		sum += int(v)
+	}
+	return float64(sum) / float64(len(gray.Pix))
+}
+
+// invert 反色，用于识别暗色模式下的浅色文字
+func invert(gray *image.Gray) {
+	for i, v := range gray.Pix {
+		gray.Pix[i] = 255 - v
+	}
+}
+
+// stretchContrast 将亮度在1%到99%分位之间的像素线性拉伸到 0~255，忽略少量极端值
+// 图片亮度几乎一致时不做处理并返回false
+func stretchContrast(gray *image.Gray) bool {
+	hist := histogram(gray)
+	total := len(gray.Pix)
+	lo, hi := percentile(hist, total, 0.01), percentile(hist, total, 0.99)
+	if hi-lo < 2 || (lo == 0 && hi == 255) {
+		return false
+	}
+
+	var table [256]uint8
+	for v := range table {
+		switch {
+		case v <= lo:
+			table[v] = 0
+		case v >= hi:
+			table[v] = 255
+		default:
+			table[v] = uint8((v - lo) * 255 / (hi - lo))
+		}
+	}
+	for i, v := range gray.Pix {
+		gray.Pix[i] = table[v]
+	}
+	return true
+}
+
+// percentile 返回累计像素数达到 p 比例时的亮度
+func percentile(hist [256]int, total int, p float64) int {
+	target := int(float64(total) * p)
+	count := 0
+	for v, n := range hist {
+		count += n
+		if count > target {
+			return v
+		}
+	}
+	return 255
+}
+
+// otsuThreshold 使用大津法计算使前景和背景类间方差最大的阈值
+func otsuThreshold(gray *image.Gray) uint8 {
+	hist := histogram(gray)
+	total := len(gray.Pix)
+
+	var sum float64
+	for v, n := range hist {
+		sum += float64(v * n)
+	}
+
+	var sumBackground, maxVariance float64
+	var weightBackground int
+	var threshold uint8
+	for v, n := range hist {
+		weightBackground += n
+		if weightBackground == 0 {
+			continue
+		}
+		weightForeground := total - weightBackground
+		if weightForeground == 0 {
+			break
+		}
+		sumBackground += float64(v * n)
+		meanBackground := sumBackground / float64(weightBackground)
+		meanForeground := (sum - sumBackground) / float64(weightForeground)
+		variance := float64(weightBackground) * float64(weightForeground) * (meanBackground - meanForeground) * (meanBackground - meanForeground)
+		if variance > maxVariance {
+			maxVariance = variance
+			threshold = uint8(v)
+		}
+	}
+	return threshold
+}
+
+// binarize 亮度大于阈值的像素设为白色，其余设为黑色
+func binarize(gray *image.Gray, threshold uint8) {
+	for i, v := range gray.Pix {
+		if v > threshold {
+			gray.Pix[i] = 255
+		} else {
+			gray.Pix[i] = 0
+		}
+	}
+}
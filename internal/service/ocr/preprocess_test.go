@@ -0,0 +1,119 @@
+package ocr
+
+import (
+	"domain-analyzer/config"
+	"image"
+	"testing"
+)
+
+// grayOf 生成按顺序填充给定亮度的 n×1 灰度图
+func grayOf(values ...uint8) *image.Gray {
+	gray := image.NewGray(image.Rect(0, 0, len(values), 1))
+	copy(gray.Pix, values)
+	return gray
+}
+
+func TestOtsuThreshold(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []uint8
+		min    uint8
+		max    uint8
+	}{
+		{name: "dark text on light background", values: []uint8{20, 25, 30, 220, 225, 230, 235, 240}, min: 30, max: 219},
+		{name: "light text on dark background", values: []uint8{10, 12, 14, 16, 18, 200, 210}, min: 18, max: 199},
+		{name: "uniform", values: []uint8{128, 128, 128}, min: 0, max: 0},
+	}
+	for _, tt := range tests {
+		if got := otsuThreshold(grayOf(tt.values...)); got < tt.min || got > tt.max {
+			t.Errorf("%s: otsuThreshold() = %d, want between %d and %d", tt.name, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestBinarize(t *testing.T) {
+	gray := grayOf(20, 100, 101, 230)
+	binarize(gray, 100)
+	want := []uint8{0, 0, 255, 255}
+	for i, v := range want {
+		if gray.Pix[i] != v {
+			t.Fatalf("binarize() = %v, want %v", gray.Pix, want)
+		}
+	}
+}
+
+func TestStretchContrast(t *testing.T) {
+	values := make([]uint8, 0, 100)
+	for i := 0; i < 50; i++ {
+		values = append(values, 100)
+	}
+	for i := 0; i < 50; i++ {
+		values = append(values, 150)
+	}
+	gray := grayOf(values...)
+	if !stretchContrast(gray) {
+		t.Fatalf("stretchContrast() = false, want true")
+	}
+	if gray.Pix[0] != 0 || gray.Pix[99] != 255 {
+		t.Errorf("stretchContrast() range = %d~%d, want 0~255", gray.Pix[0], gray.Pix[99])
+	}
+
+	// 亮度几乎一致或已经覆盖全部范围时不处理
+	if stretchContrast(grayOf(128, 128, 129)) {
+		t.Errorf("stretchContrast() on uniform image = true, want false")
+	}
+	full := grayOf(0, 255)
+	if stretchContrast(full) {
+		t.Errorf("stretchContrast() on full range image = true, want false")
+	}
+}
+
+func TestPreprocessImageInvert(t *testing.T) {
+	img := grayOf(10, 20, 250)
+	processed, _, steps := preprocessImage(img, config.Preprocess{Invert: invertAuto}, maxUpscale)
+	if got := processed.(*image.Gray).Pix[0]; got != 245 {
+		t.Errorf("preprocessImage() dark image pixel = %d, want 245 after invert", got)
+	}
+	if len(steps) != 2 || steps[1] != "invert" {
+		t.Errorf("preprocessImage() steps = %v, want [grayscale invert]", steps)
+	}
+}
+
+func TestPreprocessImageUpscaleLimit(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 100, 50))
+	options := config.Preprocess{MinWidth: 1000}
+
+	_, scale, _ := preprocessImage(img, options, 10)
+	if scale != maxUpscale {
+		t.Errorf("preprocessImage() scale = %v, want %v", scale, maxUpscale)
+	}
+	processed, scale, _ := preprocessImage(img, options, 2)
+	if scale != 2 || processed.Bounds().Dx() != 200 {
+		t.Errorf("preprocessImage() with max scale 2 = x%v, width %d", scale, processed.Bounds().Dx())
+	}
+	if _, scale, steps := preprocessImage(img, options, 1); scale != 1 || len(steps) != 0 {
+		t.Errorf("preprocessImage() with max scale 1 = x%v, steps %v, want no upscale", scale, steps)
+	}
+}
+
+func TestPreprocessMaxScale(t *testing.T) {
+	p := &preprocessedOCR{maxPixels: 1_000_000}
+	if got := p.maxScale(image.Rect(0, 0, 100, 100)); got != 10 {
+		t.Errorf("maxScale(100x100) = %v, want 10 limited by pixels", got)
+	}
+	if got := p.maxScale(image.Rect(0, 0, 20, 5000)); got != 2 {
+		t.Errorf("maxScale(20x5000) = %v, want 2 limited by side length", got)
+	}
+}
+
+func TestWithPreprocessRejectsUnknownInvert(t *testing.T) {
+	validator := NewImageValidator(&config.Config{})
+	if _, err := withPreprocess(nil, config.Preprocess{Invert: "dark"}, validator); err == nil {
+		t.Errorf("withPreprocess() with invert \"dark\" should return an error")
+	}
+	for _, invert := range []string{"", invertAuto, invertAlways} {
+		if _, err := withPreprocess(nil, config.Preprocess{Invert: invert}, validator); err != nil {
+			t.Errorf("withPreprocess() with invert %q error: %v", invert, err)
+		}
+	}
+}
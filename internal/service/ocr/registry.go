@@ -0,0 +1,60 @@
+package ocr
+
+import (
+	"domain-analyzer/config"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Factory 根据配置创建OCR服务
+type Factory func(config *config.Config) (OCRService, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{
+		"tencent": func(config *config.Config) (OCRService, error) {
+			return NewTencentOCR(config)
+		},
+		"tesseract": func(config *config.Config) (OCRService, error) {
+			return NewTesseractOCR(config)
+		},
+		"http": func(config *config.Config) (OCRService, error) {
+			return NewHTTPOCR(config)
+		},
+	}
+)
+
+// Register 注册OCR服务，同名的服务会被替换
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+// Providers 返回已注册的OCR服务名称
+func Providers() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New 按名称创建已注册的OCR服务
+func New(name string, config *config.Config) (OCRService, error) {
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown ocr provider: %s (available: %v)", name, Providers())
+	}
+	service, err := factory(config)
+	if err != nil {
+		return nil, fmt.Errorf("init ocr provider %s failed: %w", name, err)
+	}
+	return service, nil
+}
@@ -3,18 +3,20 @@ package ocr
 import (
 	"context"
 	"domain-analyzer/config"
-	"domain-analyzer/internal/pkg/domainutil"
+	"domain-analyzer/internal/model"
+	"domain-analyzer/internal/pkg/errors"
 	"encoding/base64"
-	"net/url"
 
 	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	sdkerrors "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/errors"
 	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
 	ocr "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/ocr/v20181119"
 )
 
 // TencentOCR 腾讯云OCR服务实现
 type TencentOCR struct {
-	client *ocr.Client
+	client  *ocr.Client
+	options TencentOptions
 }
 
 // NewTencentOCR 创建新的腾讯云OCR服务实例
@@ -32,34 +34,100 @@ func NewTencentOCR(config *config.Config) (*TencentOCR, error) {
 		return nil, err
 	}
 
+	options := NewTencentOptions(config)
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
 	return &TencentOCR{
-		client: client,
+		client:  client,
+		options: options,
 	}, nil
 }
 
-// RecognizeDomains 实现OCRService接口，从图片中识别并提取域名
-func (t *TencentOCR) RecognizeDomains(ctx context.Context, imageBytes []byte) ([]*url.URL, error) {
-	// 将图片转换为Base64
-	base64Img := base64.StdEncoding.EncodeToString(imageBytes)
+// Recognize 实现OCRService接口，识别图片中的全部文字
+// 使用的接口和参数可以通过 WithTencentOptions 按请求覆盖
+func (t *TencentOCR) Recognize(ctx context.Context, imageBytes []byte) (*OCRResponse, error) {
+	options := t.options
+	if override, ok := tencentOptionsFrom(ctx); ok {
+		options = options.Merge(override)
+	}
 
-	// 创建通用OCR请求
-	request := ocr.NewGeneralBasicOCRRequest()
-	request.ImageBase64 = common.StringPtr(base64Img)
+	// 将图片转换为Base64
+	base64Img := common.StringPtr(base64.StdEncoding.EncodeToString(imageBytes))
+	var pdfPageNumber *uint64
+	if options.PDF() {
+		pdfPageNumber = common.Uint64Ptr(options.PDFPage())
+	}
 
 	// 调用OCR API
-	response, err := t.client.GeneralBasicOCR(request)
+	var textDetections []*ocr.TextDetection
+	var err error
+	switch options.Action {
+	case "", TencentActionBasic:
+		request := ocr.NewGeneralBasicOCRRequest()
+		request.ImageBase64 = base64Img
+		if options.LanguageType != "" {
+			request.LanguageType = common.StringPtr(options.LanguageType)
+		}
+		request.IsPdf, request.PdfPageNumber = options.IsPdf, pdfPageNumber
+		var response *ocr.GeneralBasicOCRResponse
+		if response, err = t.client.GeneralBasicOCRWithContext(ctx, request); err == nil {
+			textDetections = response.Response.TextDetections
+		}
+	case TencentActionAccurate, TencentActionHighPrecision:
+		request := ocr.NewGeneralAccurateOCRRequest()
+		request.ImageBase64 = base64Img
+		request.IsPdf, request.PdfPageNumber = options.IsPdf, pdfPageNumber
+		var response *ocr.GeneralAccurateOCRResponse
+		if response, err = t.client.GeneralAccurateOCRWithContext(ctx, request); err == nil {
+			textDetections = response.Response.TextDetections
+		}
+	case TencentActionEfficient:
+		request := ocr.NewGeneralEfficientOCRRequest()
+		request.ImageBase64 = base64Img
+		var response *ocr.GeneralEfficientOCRResponse
+		if response, err = t.client.GeneralEfficientOCRWithContext(ctx, request); err == nil {
+			textDetections = response.Response.TextDetections
+		}
+	case TencentActionFast:
+		request := ocr.NewGeneralFastOCRRequest()
+		request.ImageBase64 = base64Img
+		request.IsPdf, request.PdfPageNumber = options.IsPdf, pdfPageNumber
+		var response *ocr.GeneralFastOCRResponse
+		if response, err = t.client.GeneralFastOCRWithContext(ctx, request); err == nil {
+			textDetections = response.Response.TextDetections
+		}
+	default:
+		return nil, options.Validate()
+	}
 	if err != nil {
-		return nil, err
+		// 图片中没有文字时返回空结果，由调用方决定是否尝试其他OCR服务
+		var sdkErr *sdkerrors.TencentCloudSDKError
+		if errors.As(err, &sdkErr) && sdkErr.GetCode() == ocr.FAILEDOPERATION_IMAGENOTEXT {
+			return &OCRResponse{}, nil
+		}
+		return nil, translateTencentError(err)
 	}
 
-	// 提取所有识别出的文本
-	var texts []string
-	for _, textDetection := range response.Response.TextDetections {
-		if textDetection.DetectedText != nil {
-			texts = append(texts, *textDetection.DetectedText)
+	// 提取所有识别出的文本及其置信度和坐标
+	result := &OCRResponse{}
+	for _, textDetection := range textDetections {
+		if textDetection.DetectedText == nil {
+			continue
+		}
+		detection := Detection{Text: *textDetection.DetectedText}
+		if textDetection.Confidence != nil {
+			detection.Confidence = float64(*textDetection.Confidence)
+		}
+		for _, coord := range textDetection.Polygon {
+			if coord == nil || coord.X == nil || coord.Y == nil {
+				continue
+			}
+			detection.Polygon = append(detection.Polygon, model.Point{X: int(*coord.X), Y: int(*coord.Y)})
 		}
+		result.Detections = append(result.Detections, detection)
 	}
 
-	// 从文本中提取域名
-	return domainutil.ExtractDomains(texts)
+	return result, nil
 }
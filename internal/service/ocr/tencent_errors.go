@@ -0,0 +1,56 @@
+package ocr
+
+import (
+	"domain-analyzer/internal/pkg/errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	sdkerrors "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/errors"
+	ocr "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/ocr/v20181119"
+)
+
+// 腾讯云公共错误码的类别前缀，同一类别下的具体错误码各接口不同
+const (
+	tencentAuthFailure           = "AuthFailure"
+	tencentUnauthorizedOperation = "UnauthorizedOperation"
+	tencentResourceUnavailable   = "ResourceUnavailable"
+)
+
+// translateTencentError 将腾讯云SDK返回的错误转换为带有用户提示和HTTP状态码的错误
+// 图片本身的问题返回客户端错误，并包装 ErrEmptyImage、ErrInvalidImage；其余返回包装 ErrServiceError 的服务端错误
+func translateTencentError(err error) error {
+	var sdkErr *sdkerrors.TencentCloudSDKError
+	if !errors.As(err, &sdkErr) {
+		// 网络错误等，没有错误码
+		return errors.NewServerError("OCR服务调用失败", fmt.Errorf("%w: %v", ErrServiceError, err)).
+			WithStatus(http.StatusBadGateway)
+	}
+
+	code := sdkErr.GetCode()
+	switch {
+	case code == ocr.FAILEDOPERATION_EMPTYIMAGEERROR:
+		return errors.NewClientError("图片内容为空", fmt.Errorf("%w: %v", ErrEmptyImage, err))
+	case code == ocr.FAILEDOPERATION_IMAGESIZETOOLARGE, code == ocr.LIMITEXCEEDED_TOOLARGEFILEERROR:
+		return errors.NewClientError("图片过大，请压缩或裁剪后重新上传", fmt.Errorf("%w: %v", ErrInvalidImage, err)).
+			WithStatus(http.StatusRequestEntityTooLarge)
+	case code == ocr.FAILEDOPERATION_IMAGEDECODEFAILED, code == ocr.INVALIDPARAMETER_ENGINEIMAGEDECODEFAILED,
+		code == ocr.FAILEDOPERATION_DOWNLOADERROR:
+		return errors.NewClientError("图片格式不支持或图片已损坏，请上传PNG、JPG、BMP格式的图片", fmt.Errorf("%w: %v", ErrInvalidImage, err))
+	case code == ocr.FAILEDOPERATION_IMAGEBLUR:
+		return errors.NewClientError("图片过于模糊，请上传更清晰的截图", fmt.Errorf("%w: %v", ErrInvalidImage, err))
+	case strings.HasPrefix(code, tencentAuthFailure), strings.HasPrefix(code, tencentUnauthorizedOperation):
+		return errors.NewServerError("OCR服务鉴权失败，请检查腾讯云密钥配置", fmt.Errorf("%w: %v", ErrServiceError, err))
+	case strings.HasPrefix(code, ocr.REQUESTLIMITEXCEEDED):
+		return errors.NewServerError("OCR请求过于频繁，请稍后重试", fmt.Errorf("%w: %v", ErrServiceError, err)).
+			WithStatus(http.StatusTooManyRequests)
+	case code == ocr.FAILEDOPERATION_ARREARSERROR, code == ocr.FAILEDOPERATION_USERQUOTAERROR,
+		code == ocr.FAILEDOPERATION_COUNTLIMITERROR, code == ocr.RESOURCESSOLDOUT_CHARGESTATUSEXCEPTION,
+		strings.HasPrefix(code, tencentResourceUnavailable):
+		return errors.NewServerError("OCR服务额度已用完或账户欠费，请联系管理员", fmt.Errorf("%w: %v", ErrServiceError, err)).
+			WithStatus(http.StatusServiceUnavailable)
+	default:
+		return errors.NewServerError("OCR服务调用失败", fmt.Errorf("%w: %v", ErrServiceError, err)).
+			WithStatus(http.StatusBadGateway)
+	}
+}
@@ -0,0 +1,50 @@
+package ocr
+
+import (
+	"domain-analyzer/internal/pkg/errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	sdkerrors "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/errors"
+	ocr "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/ocr/v20181119"
+)
+
+func TestTranslateTencentError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		client bool
+		target error
+	}{
+		{err: sdkerrors.NewTencentCloudSDKError(ocr.FAILEDOPERATION_EMPTYIMAGEERROR, "empty", "req"), status: http.StatusBadRequest, client: true, target: ErrEmptyImage},
+		{err: sdkerrors.NewTencentCloudSDKError(ocr.FAILEDOPERATION_IMAGESIZETOOLARGE, "too large", "req"), status: http.StatusRequestEntityTooLarge, client: true, target: ErrInvalidImage},
+		{err: sdkerrors.NewTencentCloudSDKError(ocr.LIMITEXCEEDED_TOOLARGEFILEERROR, "too large", "req"), status: http.StatusRequestEntityTooLarge, client: true, target: ErrInvalidImage},
+		{err: sdkerrors.NewTencentCloudSDKError(ocr.FAILEDOPERATION_IMAGEDECODEFAILED, "decode", "req"), status: http.StatusBadRequest, client: true, target: ErrInvalidImage},
+		{err: sdkerrors.NewTencentCloudSDKError(ocr.FAILEDOPERATION_IMAGEBLUR, "blur", "req"), status: http.StatusBadRequest, client: true, target: ErrInvalidImage},
+		{err: sdkerrors.NewTencentCloudSDKError("AuthFailure.SignatureFailure", "sign", "req"), status: http.StatusInternalServerError, target: ErrServiceError},
+		{err: sdkerrors.NewTencentCloudSDKError("UnauthorizedOperation", "denied", "req"), status: http.StatusInternalServerError, target: ErrServiceError},
+		{err: sdkerrors.NewTencentCloudSDKError(ocr.REQUESTLIMITEXCEEDED, "limit", "req"), status: http.StatusTooManyRequests, target: ErrServiceError},
+		{err: sdkerrors.NewTencentCloudSDKError(ocr.FAILEDOPERATION_ARREARSERROR, "arrears", "req"), status: http.StatusServiceUnavailable, target: ErrServiceError},
+		{err: sdkerrors.NewTencentCloudSDKError("ResourceUnavailable.InArrears", "arrears", "req"), status: http.StatusServiceUnavailable, target: ErrServiceError},
+		{err: sdkerrors.NewTencentCloudSDKError("InternalError", "internal", "req"), status: http.StatusBadGateway, target: ErrServiceError},
+		{err: fmt.Errorf("dial tcp: connection refused"), status: http.StatusBadGateway, target: ErrServiceError},
+	}
+	for _, tt := range tests {
+		err := translateTencentError(tt.err)
+		if got := errors.HTTPStatus(err); got != tt.status {
+			t.Errorf("translateTencentError(%v) status = %d, want %d", tt.err, got, tt.status)
+		}
+		if got := errors.IsClientError(err); got != tt.client {
+			t.Errorf("translateTencentError(%v) client error = %v, want %v", tt.err, got, tt.client)
+		}
+		if !errors.Is(err, tt.target) {
+			t.Errorf("translateTencentError(%v) = %v, should wrap %v", tt.err, err, tt.target)
+		}
+		// 返回给用户的信息不包含SDK的错误细节
+		if msg := errors.Message(err); strings.Contains(msg, tt.err.Error()) {
+			t.Errorf("translateTencentError(%v) message %q leaks the sdk error", tt.err, msg)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package ocr
+
+import (
+	"context"
+	"domain-analyzer/config"
+	"domain-analyzer/internal/pkg/errors"
+	"fmt"
+	"strconv"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+)
+
+// 腾讯云OCR接口，按费用和识别准确度选择
+const (
+	TencentActionBasic         = "basic"          // 通用印刷体识别 GeneralBasicOCR
+	TencentActionAccurate      = "accurate"       // 通用印刷体识别（高精度版）GeneralAccurateOCR
+	TencentActionHighPrecision = "high-precision" // accurate 的别名
+	TencentActionEfficient     = "efficient"      // 通用印刷体识别（精简版）GeneralEfficientOCR
+	TencentActionFast          = "fast"           // 通用印刷体识别（高速版）GeneralFastOCR
+)
+
+// tencentLanguages 通用印刷体识别 basic 接口支持的识别语言
+var tencentLanguages = map[string]bool{
+	"zh": true, "zh_rare": true, "auto": true, "mix": true, "jap": true, "kor": true, "spa": true, "fre": true,
+	"ger": true, "por": true, "vie": true, "may": true, "rus": true, "ita": true, "hol": true, "swe": true,
+	"fin": true, "dan": true, "nor": true, "hun": true, "tha": true, "hi": true, "ara": true,
+}
+
+// TencentOptions 腾讯云OCR的调用参数，零值字段表示使用配置中的值
+type TencentOptions struct {
+	Action        string `json:"action,omitempty"`
+	LanguageType  string `json:"language_type,omitempty"`   // 识别语言，例如 zh、auto，只有 basic 接口支持
+	IsPdf         *bool  `json:"is_pdf,omitempty"`          // 图片数据可以是PDF文件，efficient 接口不支持
+	PdfPageNumber uint64 `json:"pdf_page_number,omitempty"` // 识别的PDF页码，默认第1页
+}
+
+// NewTencentOptions 返回配置中的腾讯云OCR调用参数
+func NewTencentOptions(config *config.Config) TencentOptions {
+	options := TencentOptions{
+		Action:        config.OCR.Tencent.Action,
+		LanguageType:  config.OCR.Tencent.LanguageType,
+		PdfPageNumber: config.OCR.Tencent.PdfPageNumber,
+	}
+	if config.OCR.Tencent.IsPdf {
+		options.IsPdf = common.BoolPtr(true)
+	}
+	return options
+}
+
+// PDF 判断是否开启了PDF识别
+func (o TencentOptions) PDF() bool {
+	return o.IsPdf != nil && *o.IsPdf
+}
+
+// PDFPage 返回开启PDF识别时识别的页码
+func (o TencentOptions) PDFPage() uint64 {
+	if o.PdfPageNumber == 0 {
+		return 1
+	}
+	return o.PdfPageNumber
+}
+
+// Validate 检查接口名称和识别语言是否有效，以及接口是否支持指定的参数，无效时返回客户端错误
+func (o TencentOptions) Validate() error {
+	switch o.Action {
+	case "", TencentActionBasic, TencentActionAccurate, TencentActionHighPrecision, TencentActionEfficient, TencentActionFast:
+	default:
+		return errors.NewClientError("不支持的OCR接口，可选 basic、accurate、high-precision、efficient、fast",
+			fmt.Errorf("unknown tencent ocr action: %s", o.Action))
+	}
+	if o.LanguageType != "" {
+		if !tencentLanguages[o.LanguageType] {
+			return errors.NewClientError(fmt.Sprintf("不支持的识别语言 %s，可选 zh、auto、mix 等", o.LanguageType),
+				fmt.Errorf("unknown tencent ocr language type: %s", o.LanguageType))
+		}
+		if o.Action != "" && o.Action != TencentActionBasic {
+			return errors.NewClientError(fmt.Sprintf("%s 接口不支持指定识别语言，只有 basic 接口支持", o.Action),
+				fmt.Errorf("tencent ocr action %s does not support language type", o.Action))
+		}
+	}
+	if o.PDF() && o.Action == TencentActionEfficient {
+		return errors.NewClientError("efficient 接口不支持识别PDF", fmt.Errorf("tencent ocr action %s does not support pdf", o.Action))
+	}
+	return nil
+}
+
+// Merge 使用 override 中的非零字段覆盖当前参数
+func (o TencentOptions) Merge(override TencentOptions) TencentOptions {
+	if override.Action != "" {
+		o.Action = override.Action
+	}
+	if override.LanguageType != "" {
+		o.LanguageType = override.LanguageType
+	}
+	if override.IsPdf != nil {
+		o.IsPdf = override.IsPdf
+	}
+	if override.PdfPageNumber != 0 {
+		o.PdfPageNumber = override.PdfPageNumber
+	}
+	return o
+}
+
+// key 返回参数的字符串表示，用于区分不同参数下的缓存结果
+func (o TencentOptions) key() string {
+	isPdf := ""
+	if o.IsPdf != nil {
+		isPdf = strconv.FormatBool(*o.IsPdf)
+	}
+	return fmt.Sprintf("tencent:action=%s;language=%s;pdf=%s;page=%d", o.Action, o.LanguageType, isPdf, o.PdfPageNumber)
+}
+
+type tencentOptionsKey struct{}
+
+// WithTencentOptions 返回单次请求覆盖腾讯云OCR调用参数的context
+func WithTencentOptions(ctx context.Context, options TencentOptions) context.Context {
+	return context.WithValue(ctx, tencentOptionsKey{}, options)
+}
+
+// tencentOptionsFrom 返回context中覆盖的调用参数
+func tencentOptionsFrom(ctx context.Context) (TencentOptions, bool) {
+	options, ok := ctx.Value(tencentOptionsKey{}).(TencentOptions)
+	return options, ok
+}
@@ -0,0 +1,71 @@
+package ocr
+
+import (
+	"domain-analyzer/internal/pkg/errors"
+	"testing"
+)
+
+func TestTencentOptionsValidate(t *testing.T) {
+	pdf, noPdf := true, false
+	tests := []struct {
+		options TencentOptions
+		valid   bool
+	}{
+		{options: TencentOptions{}, valid: true},
+		{options: TencentOptions{Action: TencentActionAccurate}, valid: true},
+		{options: TencentOptions{LanguageType: "auto"}, valid: true},
+		{options: TencentOptions{Action: TencentActionBasic, LanguageType: "jap"}, valid: true},
+		{options: TencentOptions{Action: "handwriting"}},
+		{options: TencentOptions{LanguageType: "english"}},
+		{options: TencentOptions{Action: TencentActionAccurate, LanguageType: "auto"}},
+		{options: TencentOptions{Action: TencentActionFast, LanguageType: "zh"}},
+		{options: TencentOptions{Action: TencentActionAccurate, IsPdf: &pdf, PdfPageNumber: 2}, valid: true},
+		{options: TencentOptions{Action: TencentActionEfficient, IsPdf: &pdf}},
+		{options: TencentOptions{Action: TencentActionEfficient, IsPdf: &noPdf}, valid: true},
+	}
+	for _, tt := range tests {
+		err := tt.options.Validate()
+		if tt.valid {
+			if err != nil {
+				t.Errorf("Validate(%+v) error: %v", tt.options, err)
+			}
+			continue
+		}
+		if !errors.IsClientError(err) {
+			t.Errorf("Validate(%+v) = %v, want client error", tt.options, err)
+		}
+	}
+}
+
+func TestTencentOptionsMerge(t *testing.T) {
+	configured := TencentOptions{Action: TencentActionAccurate}
+	merged := configured.Merge(TencentOptions{LanguageType: "auto"})
+	if merged.Action != TencentActionAccurate || merged.LanguageType != "auto" {
+		t.Errorf("Merge() = %+v", merged)
+	}
+	// 配置了 accurate 接口时只覆盖识别语言也是无效的
+	if err := merged.Validate(); err == nil {
+		t.Errorf("Validate(%+v) should reject language type with the accurate action", merged)
+	}
+	if merged.key() == configured.key() {
+		t.Errorf("key() should differ when the language type differs")
+	}
+}
+
+func TestTencentOptionsPDF(t *testing.T) {
+	on, off := true, false
+	configured := TencentOptions{IsPdf: &on}
+	if !configured.PDF() || configured.PDFPage() != 1 {
+		t.Errorf("PDF() = %v, PDFPage() = %d, want true and page 1", configured.PDF(), configured.PDFPage())
+	}
+	// 请求可以关闭配置中开启的PDF识别
+	if merged := configured.Merge(TencentOptions{IsPdf: &off}); merged.PDF() {
+		t.Errorf("Merge() with is_pdf=false = %+v, want pdf disabled", merged)
+	}
+	if merged := configured.Merge(TencentOptions{PdfPageNumber: 3}); !merged.PDF() || merged.PDFPage() != 3 {
+		t.Errorf("Merge() with page 3 = %+v", merged)
+	}
+	if configured.key() == (TencentOptions{}).key() || configured.key() == configured.Merge(TencentOptions{PdfPageNumber: 2}).key() {
+		t.Errorf("key() should differ for pdf options")
+	}
+}
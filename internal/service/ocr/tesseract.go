@@ -0,0 +1,164 @@
+package ocr
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"domain-analyzer/config"
+	"domain-analyzer/internal/model"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TesseractOCR 调用本地 tesseract 命令的OCR服务实现，不依赖云服务，可离线使用
+type TesseractOCR struct {
+	path      string
+	languages string
+	psm       int
+	timeout   time.Duration
+}
+
+// NewTesseractOCR 创建新的Tesseract OCR服务实例，tesseract 命令不存在时返回错误
+func NewTesseractOCR(config *config.Config) (*TesseractOCR, error) {
+	cfg := config.OCR.Tesseract
+	t := &TesseractOCR{
+		path:      "tesseract",
+		languages: "eng",
+		psm:       cfg.PSM,
+		timeout:   30 * time.Second,
+	}
+	if cfg.Path != "" {
+		t.path = cfg.Path
+	}
+	if cfg.Languages != "" {
+		t.languages = cfg.Languages
+	}
+	if cfg.TimeoutSeconds > 0 {
+		t.timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+
+	path, err := exec.LookPath(t.path)
+	if err != nil {
+		return nil, fmt.Errorf("tesseract not found: %w", err)
+	}
+	t.path = path
+	return t, nil
+}
+
+// Recognize 实现OCRService接口，识别图片中的全部文字
+func (t *TesseractOCR) Recognize(ctx context.Context, imageBytes []byte) (*OCRResponse, error) {
+	if len(imageBytes) == 0 {
+		return nil, rejectImage(ErrEmptyImage)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	// 从标准输入读取图片，以TSV格式输出每个单词的位置和置信度
+	args := []string{"stdin", "stdout", "-l", t.languages}
+	if t.psm > 0 {
+		args = append(args, "--psm", strconv.Itoa(t.psm))
+	}
+	args = append(args, "tsv")
+
+	cmd := exec.CommandContext(ctx, t.path, args...)
+	cmd.Stdin = bytes.NewReader(imageBytes)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%w: tesseract: %v", ErrServiceError, ctx.Err())
+		}
+		return nil, fmt.Errorf("%w: tesseract: %v: %s", ErrServiceError, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return parseTesseractTSV(stdout.Bytes())
+}
+
+// tesseractLine 表示TSV输出中的一行文本，由同一行的单词合并而成
+type tesseractLine struct {
+	words                    []string
+	confidence               float64
+	left, top, right, bottom int
+}
+
+// parseTesseractTSV 解析 tesseract 的TSV输出，将单词按行合并
+// 列依次为 level page_num block_num par_num line_num word_num left top width height conf text
+func parseTesseractTSV(data []byte) (*OCRResponse, error) {
+	var keys []string
+	lines := make(map[string]*tesseractLine)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for first := true; scanner.Scan(); first = false {
+		if first {
+			continue // 表头
+		}
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 12 || fields[0] != "5" {
+			continue // 只处理单词级别的记录
+		}
+		text := strings.TrimSpace(strings.Join(fields[11:], "\t"))
+		if text == "" {
+			continue
+		}
+
+		var nums [4]int
+		for i, field := range fields[6:10] {
+			n, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid tesseract output: %v", ErrServiceError, err)
+			}
+			nums[i] = n
+		}
+		conf, err := strconv.ParseFloat(fields[10], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid tesseract output: %v", ErrServiceError, err)
+		}
+		left, top, right, bottom := nums[0], nums[1], nums[0]+nums[2], nums[1]+nums[3]
+
+		key := strings.Join(fields[1:5], "-")
+		line, ok := lines[key]
+		if !ok {
+			line = &tesseractLine{left: left, top: top, right: right, bottom: bottom}
+			lines[key] = line
+			keys = append(keys, key)
+		}
+		line.words = append(line.words, text)
+		line.confidence += conf
+		if left < line.left {
+			line.left = left
+		}
+		if top < line.top {
+			line.top = top
+		}
+		if right > line.right {
+			line.right = right
+		}
+		if bottom > line.bottom {
+			line.bottom = bottom
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrServiceError, err)
+	}
+
+	result := &OCRResponse{}
+	for _, key := range keys {
+		line := lines[key]
+		result.Detections = append(result.Detections, Detection{
+			Text:       strings.Join(line.words, " "),
+			Confidence: line.confidence / float64(len(line.words)),
+			Polygon: []model.Point{
+				{X: line.left, Y: line.top},
+				{X: line.right, Y: line.top},
+				{X: line.right, Y: line.bottom},
+				{X: line.left, Y: line.bottom},
+			},
+		})
+	}
+	return result, nil
+}
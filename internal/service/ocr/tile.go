@@ -0,0 +1,239 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"domain-analyzer/config"
+	"domain-analyzer/internal/model"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+	"sync"
+)
+
+// 默认的切图参数
+const (
+	defaultTileHeight  = 4000
+	defaultTileOverlap = 200
+	defaultTileWorkers = 4
+)
+
+// edgeMargin 距离切分边缘小于该像素数的文本行视为被切断
+const edgeMargin = 2
+
+// tiledOCR 将过长的截图切分为互相重叠的小图分别识别，再合并识别结果
+// 重叠区域的高度应大于一行文字的高度，保证每行文字至少在一张小图中是完整的
+type tiledOCR struct {
+	service    OCRService
+	tileHeight int
+	overlap    int
+	workers    int
+}
+
+// withTiling 为OCR服务添加切图识别
+func withTiling(service OCRService, config *config.Config) OCRService {
+	cfg := config.OCR.Tiling
+	t := &tiledOCR{
+		service:    service,
+		tileHeight: defaultTileHeight,
+		overlap:    defaultTileOverlap,
+		workers:    defaultTileWorkers,
+	}
+	if cfg.TileHeight > 0 {
+		t.tileHeight = cfg.TileHeight
+	}
+	if cfg.Overlap > 0 {
+		t.overlap = cfg.Overlap
+	}
+	if cfg.Workers > 0 {
+		t.workers = cfg.Workers
+	}
+	if t.overlap >= t.tileHeight/2 {
+		t.overlap = t.tileHeight / 2
+	}
+	return t
+}
+
+// tile 表示切分出的一张小图，top/bottom 为在原图中的纵坐标范围
+type tile struct {
+	top, bottom int
+	resp        *OCRResponse
+	err         error
+}
+
+// Recognize 实现OCRService接口，高度不超过 tileHeight 的图片直接识别
+func (t *tiledOCR) Recognize(ctx context.Context, imageBytes []byte) (*OCRResponse, error) {
+	if isPDF(imageBytes) {
+		return t.service.Recognize(ctx, imageBytes)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(imageBytes))
+	if err != nil {
+		return nil, rejectImage(fmt.Errorf("%w: %v", ErrInvalidImage, err))
+	}
+	if cfg.Height <= t.tileHeight {
+		return t.service.Recognize(ctx, imageBytes)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(imageBytes))
+	if err != nil {
+		return nil, rejectImage(fmt.Errorf("%w: %v", ErrInvalidImage, err))
+	}
+	tiles := splitTiles(img.Bounds().Dy(), t.tileHeight, t.overlap)
+
+	// 并发识别各个小图
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	jobs := make(chan *tile)
+	var wg sync.WaitGroup
+	for i := 0; i < t.workers && i < len(tiles); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for tl := range jobs {
+				tl.resp, tl.err = t.recognizeTile(ctx, img, tl)
+				if tl.err != nil {
+					cancel()
+				}
+			}
+		}()
+	}
+	for _, tl := range tiles {
+		jobs <- tl
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, tl := range tiles {
+		if tl.err != nil {
+			return nil, tl.err
+		}
+	}
+	return mergeTiles(tiles), nil
+}
+
+// recognizeTile 识别单张小图，坐标换算为原图坐标
+func (t *tiledOCR) recognizeTile(ctx context.Context, img image.Image, tl *tile) (*OCRResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	bounds := img.Bounds()
+	rect := image.Rect(bounds.Min.X, bounds.Min.Y+tl.top, bounds.Max.X, bounds.Min.Y+tl.bottom)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, subImage(img, rect)); err != nil {
+		return nil, fmt.Errorf("%w: encode tile failed: %v", ErrServiceError, err)
+	}
+	resp, err := t.service.Recognize(ctx, buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	for i := range resp.Detections {
+		for j := range resp.Detections[i].Polygon {
+			resp.Detections[i].Polygon[j].Y += tl.top
+		}
+	}
+	return resp, nil
+}
+
+// subImage 截取图片的一部分，不支持截取的图片类型会先复制为RGBA
+func subImage(img image.Image, rect image.Rectangle) image.Image {
+	if s, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	}); ok {
+		return s.SubImage(rect)
+	}
+	dst := image.NewRGBA(rect)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			dst.Set(x, y, img.At(x, y))
+		}
+	}
+	return dst
+}
+
+// splitTiles 将高度为 height 的图片切分为高度不超过 tileHeight、相邻之间重叠 overlap 的小图
+func splitTiles(height, tileHeight, overlap int) []*tile {
+	var tiles []*tile
+	for top := 0; ; top += tileHeight - overlap {
+		bottom := top + tileHeight
+		if bottom >= height {
+			tiles = append(tiles, &tile{top: top, bottom: height})
+			return tiles
+		}
+		tiles = append(tiles, &tile{top: top, bottom: bottom})
+	}
+}
+
+// mergeTiles 按小图顺序合并识别结果
+// 被切分边缘切断的文本行会在相邻小图中完整出现，因此直接丢弃；重叠区域中重复识别的文本行只保留一次
+func mergeTiles(tiles []*tile) *OCRResponse {
+	merged := &OCRResponse{}
+	for i, tl := range tiles {
+		if i == 0 {
+			merged.Preprocessing = tl.resp.Preprocessing
+		}
+		for _, d := range tl.resp.Detections {
+			top, bottom, ok := verticalRange(d.Polygon)
+			if ok {
+				if i > 0 && top <= tl.top+edgeMargin {
+					continue
+				}
+				if i < len(tiles)-1 && bottom >= tl.bottom-edgeMargin {
+					continue
+				}
+			}
+			if ok && isDuplicateDetection(merged.Detections, d, top, bottom) {
+				continue
+			}
+			merged.Detections = append(merged.Detections, d)
+		}
+	}
+	return merged
+}
+
+// isDuplicateDetection 判断已合并的结果中是否有内容相同、纵向位置基本重合的文本行
+func isDuplicateDetection(detections []Detection, d Detection, top, bottom int) bool {
+	text := strings.TrimSpace(d.Text)
+	for _, other := range detections {
+		if strings.TrimSpace(other.Text) != text {
+			continue
+		}
+		otherTop, otherBottom, ok := verticalRange(other.Polygon)
+		if !ok {
+			continue
+		}
+		overlap := minInt(bottom, otherBottom) - maxInt(top, otherTop)
+		if overlap*2 > minInt(bottom-top, otherBottom-otherTop) {
+			return true
+		}
+	}
+	return false
+}
+
+// verticalRange 返回文本框的纵坐标范围
+func verticalRange(polygon []model.Point) (top, bottom int, ok bool) {
+	if len(polygon) == 0 {
+		return 0, 0, false
+	}
+	top, bottom = polygon[0].Y, polygon[0].Y
+	for _, p := range polygon[1:] {
+		top = minInt(top, p.Y)
+		bottom = maxInt(bottom, p.Y)
+	}
+	return top, bottom, true
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,135 @@
+package ocr
+
+import (
+	"context"
+	"domain-analyzer/config"
+	"domain-analyzer/internal/model"
+	"domain-analyzer/internal/pkg/errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSplitTiles(t *testing.T) {
+	tests := []struct {
+		height, tileHeight, overlap int
+		want                        [][2]int
+	}{
+		{height: 100, tileHeight: 100, overlap: 10, want: [][2]int{{0, 100}}},
+		{height: 250, tileHeight: 100, overlap: 10, want: [][2]int{{0, 100}, {90, 190}, {180, 250}}},
+		{height: 190, tileHeight: 100, overlap: 10, want: [][2]int{{0, 100}, {90, 190}}},
+		{height: 300, tileHeight: 100, overlap: 0, want: [][2]int{{0, 100}, {100, 200}, {200, 300}}},
+	}
+	for _, tt := range tests {
+		tiles := splitTiles(tt.height, tt.tileHeight, tt.overlap)
+		if len(tiles) != len(tt.want) {
+			t.Errorf("splitTiles(%d, %d, %d) = %d tiles, want %d", tt.height, tt.tileHeight, tt.overlap, len(tiles), len(tt.want))
+			continue
+		}
+		for i, tl := range tiles {
+			if tl.top != tt.want[i][0] || tl.bottom != tt.want[i][1] {
+				t.Errorf("splitTiles(%d, %d, %d)[%d] = %d~%d, want %d~%d", tt.height, tt.tileHeight, tt.overlap, i, tl.top, tl.bottom, tt.want[i][0], tt.want[i][1])
+			}
+		}
+	}
+}
+
+// line 生成纵坐标范围为 top~bottom 的文本行，坐标为原图坐标
+func line(text string, top, bottom int) Detection {
+	return Detection{Text: text, Polygon: []model.Point{{X: 0, Y: top}, {X: 100, Y: top}, {X: 100, Y: bottom}, {X: 0, Y: bottom}}}
+}
+
+func TestMergeTiles(t *testing.T) {
+	tiles := []*tile{
+		{top: 0, bottom: 100, resp: &OCRResponse{Detections: []Detection{
+			line("a.com", 10, 30),
+			line("b.com", 88, 99), // 被下边缘切断
+		}}},
+		{top: 90, bottom: 190, resp: &OCRResponse{Detections: []Detection{
+			line("a.com", 91, 95), // 被上边缘切断
+			line("b.com", 93, 110),
+			line("c.com", 183, 187),
+			{Text: "no polygon"},
+		}}},
+		{top: 180, bottom: 250, resp: &OCRResponse{Detections: []Detection{
+			line("c.com", 184, 187), // 重叠区域中重复识别
+			line("d.com", 182, 200),
+			line("e.com", 240, 249), // 最后一张小图的下边缘是原图的边缘
+		}}},
+	}
+	merged := mergeTiles(tiles)
+
+	var got []string
+	for _, d := range merged.Detections {
+		got = append(got, d.Text)
+	}
+	want := []string{"a.com", "b.com", "c.com", "no polygon", "e.com"}
+	if len(got) != len(want) {
+		t.Fatalf("mergeTiles() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("mergeTiles() = %v, want %v", got, want)
+		}
+	}
+}
+
+// slowOCR 每次调用等待 delay 或ctx结束，记录调用次数
+type slowOCR struct {
+	delay time.Duration
+	calls int32
+}
+
+func (s *slowOCR) Recognize(ctx context.Context, imageBytes []byte) (*OCRResponse, error) {
+	atomic.AddInt32(&s.calls, 1)
+	select {
+	case <-time.After(s.delay):
+		return &OCRResponse{}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestTiledTimeoutPerTile(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.OCR.Tiling.TileHeight = 100
+	cfg.OCR.Tiling.Overlap = 10
+	cfg.OCR.Tiling.Workers = 1
+	image := encodePNG(t, 50, 450)
+
+	// 每张小图都在超时之前返回，总耗时超过单次超时也能完成
+	fast := &slowOCR{delay: 20 * time.Millisecond}
+	if _, err := withTiling(withTimeout("slow", fast, 50*time.Millisecond), cfg).Recognize(context.Background(), image); err != nil {
+		t.Fatalf("Recognize() error: %v", err)
+	}
+	if fast.calls != 5 {
+		t.Errorf("Recognize() called the service %d times, want 5", fast.calls)
+	}
+
+	// 一张小图超时后不再识别其余的小图
+	slow := &slowOCR{delay: time.Second}
+	_, err := withTiling(withTimeout("slow", slow, 20*time.Millisecond), cfg).Recognize(context.Background(), image)
+	if errors.HTTPStatus(err) != http.StatusGatewayTimeout {
+		t.Errorf("Recognize() error = %v, want a gateway timeout", err)
+	}
+	if slow.calls != 1 {
+		t.Errorf("Recognize() called the service %d times after a timeout, want 1", slow.calls)
+	}
+}
+
+func TestPDFSkipsTilingAndPreprocess(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.OCR.Tiling.TileHeight = 100
+	service := &fixedOCR{resp: &OCRResponse{}}
+	preprocessed, err := withPreprocess(service, config.Preprocess{Grayscale: true, MinWidth: 1000}, NewImageValidator(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := withTiling(preprocessed, cfg).Recognize(context.Background(), []byte("%PDF-1.4\n")); err != nil {
+		t.Errorf("Recognize() on a pdf file error: %v", err)
+	}
+	if service.calls != 1 {
+		t.Errorf("service called %d times, want 1", service.calls)
+	}
+}
@@ -0,0 +1,154 @@
+package ocr
+
+import (
+	"bytes"
+	"domain-analyzer/config"
+	"domain-analyzer/internal/pkg/errors"
+	"fmt"
+	"image"
+	"net/http"
+
+	// 注册支持的图片格式解码器
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/bmp"
+)
+
+// 默认的图片限制，与腾讯云OCR的要求一致：Base64编码后不超过10M，短边不小于20像素
+const (
+	defaultMaxImageBytes  = 7 << 20
+	defaultMinImageSide   = 20
+	defaultMaxImagePixels = 50_000_000
+)
+
+// 长截图切分为小图后识别，只需要限制整张图片解码时占用的内存，6000万像素的RGBA图片约占240MB
+const (
+	defaultMaxLongImageBytes  = 32 << 20
+	defaultMaxLongImagePixels = 60_000_000
+)
+
+// supportedImageTypes 允许上传的图片类型，按文件内容嗅探
+var supportedImageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/bmp":  true,
+	"image/gif":  true,
+}
+
+// isPDF 判断数据是否为PDF文件，开启腾讯云PDF识别时PDF文件直接提交给OCR服务，不切图也不预处理
+func isPDF(data []byte) bool {
+	return http.DetectContentType(data) == "application/pdf"
+}
+
+// ImageInfo 表示通过校验的图片信息
+type ImageInfo struct {
+	Format string // png、jpeg、bmp、gif
+	Width  int
+	Height int
+}
+
+// ImageValidator 在调用OCR服务前校验上传的图片，避免为无效图片消耗OCR调用
+// 高度超过切图高度的长截图不会整张提交给OCR服务，按每张小图的尺寸校验像素数，文件大小使用单独的上限
+type ImageValidator struct {
+	maxBytes      int64
+	minSide       int
+	maxPixels     int
+	maxLongBytes  int64
+	maxLongPixels int
+	tileHeight    int
+}
+
+// NewImageValidator 根据配置创建图片校验器，未配置的限制使用默认值
+func NewImageValidator(config *config.Config) *ImageValidator {
+	cfg := config.Upload
+	v := &ImageValidator{
+		maxBytes:      defaultMaxImageBytes,
+		minSide:       defaultMinImageSide,
+		maxPixels:     defaultMaxImagePixels,
+		maxLongBytes:  defaultMaxLongImageBytes,
+		maxLongPixels: defaultMaxLongImagePixels,
+		tileHeight:    defaultTileHeight,
+	}
+	if cfg.MaxImageBytes > 0 {
+		v.maxBytes = cfg.MaxImageBytes
+	}
+	if cfg.MinImageSide > 0 {
+		v.minSide = cfg.MinImageSide
+	}
+	if cfg.MaxImagePixels > 0 {
+		v.maxPixels = cfg.MaxImagePixels
+	}
+	if cfg.MaxLongImageBytes > 0 {
+		v.maxLongBytes = cfg.MaxLongImageBytes
+	}
+	if cfg.MaxLongImagePixels > 0 {
+		v.maxLongPixels = cfg.MaxLongImagePixels
+	}
+	if config.OCR.Tiling.TileHeight > 0 {
+		v.tileHeight = config.OCR.Tiling.TileHeight
+	}
+	if v.maxLongBytes < v.maxBytes {
+		v.maxLongBytes = v.maxBytes
+	}
+	return v
+}
+
+// MaxBytes 返回允许的图片文件大小上限，包括长截图
+func (v *ImageValidator) MaxBytes() int64 {
+	return v.maxLongBytes
+}
+
+// Validate 校验图片的类型、大小和像素尺寸
+// 只读取图片头，不解码整张图片；图片数据损坏时由切图、预处理或OCR服务在解码时报错
+// 校验失败时返回包装 ErrEmptyImage 或 ErrInvalidImage 的客户端错误，OCR服务链遇到这类错误不再尝试其他服务
+func (v *ImageValidator) Validate(data []byte) (*ImageInfo, error) {
+	if len(data) == 0 {
+		return nil, errors.NewClientError("图片内容为空", rejectImage(ErrEmptyImage))
+	}
+	if int64(len(data)) > v.maxLongBytes {
+		return nil, errors.NewClientError(fmt.Sprintf("图片过大，不能超过 %.1fMB", float64(v.maxLongBytes)/(1<<20)),
+			rejectImage(fmt.Errorf("%w: size %d exceeds %d bytes", ErrInvalidImage, len(data), v.maxLongBytes)))
+	}
+
+	contentType := http.DetectContentType(data)
+	if !supportedImageTypes[contentType] {
+		return nil, errors.NewClientError("不支持的图片格式，请上传PNG、JPG、BMP或GIF格式的图片",
+			rejectImage(fmt.Errorf("%w: content type %s", ErrInvalidImage, contentType)))
+	}
+
+	// 先读取图片头中的尺寸，避免解码超大图片
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.NewClientError("图片已损坏，无法读取", rejectImage(fmt.Errorf("%w: %v", ErrInvalidImage, err)))
+	}
+	if cfg.Width < v.minSide || cfg.Height < v.minSide {
+		return nil, errors.NewClientError(fmt.Sprintf("图片尺寸过小，宽和高不能小于 %d 像素", v.minSide),
+			rejectImage(fmt.Errorf("%w: size %dx%d", ErrInvalidImage, cfg.Width, cfg.Height)))
+	}
+
+	if cfg.Height <= v.tileHeight {
+		// 整张提交给OCR服务
+		if int64(len(data)) > v.maxBytes {
+			return nil, errors.NewClientError(fmt.Sprintf("图片过大，不能超过 %.1fMB", float64(v.maxBytes)/(1<<20)),
+				rejectImage(fmt.Errorf("%w: size %d exceeds %d bytes", ErrInvalidImage, len(data), v.maxBytes)))
+		}
+		if cfg.Width*cfg.Height > v.maxPixels {
+			return nil, errors.NewClientError(fmt.Sprintf("图片尺寸过大，像素总数不能超过 %d", v.maxPixels),
+				rejectImage(fmt.Errorf("%w: size %dx%d", ErrInvalidImage, cfg.Width, cfg.Height)))
+		}
+	} else {
+		// 长截图按切图高度分别提交，每张小图的像素数不能超过限制
+		if cfg.Width*v.tileHeight > v.maxPixels {
+			return nil, errors.NewClientError(fmt.Sprintf("长截图过宽，宽度不能超过 %d 像素", v.maxPixels/v.tileHeight),
+				rejectImage(fmt.Errorf("%w: size %dx%d", ErrInvalidImage, cfg.Width, cfg.Height)))
+		}
+		if cfg.Width*cfg.Height > v.maxLongPixels {
+			return nil, errors.NewClientError(fmt.Sprintf("长截图尺寸过大，像素总数不能超过 %d", v.maxLongPixels),
+				rejectImage(fmt.Errorf("%w: size %dx%d", ErrInvalidImage, cfg.Width, cfg.Height)))
+		}
+	}
+
+	return &ImageInfo{Format: format, Width: cfg.Width, Height: cfg.Height}, nil
+}
@@ -0,0 +1,84 @@
+package ocr
+
+import (
+	"bytes"
+	"domain-analyzer/config"
+	"domain-analyzer/internal/pkg/errors"
+	"image"
+	"image/png"
+	"net/http"
+	"testing"
+)
+
+// encodePNG 生成指定尺寸的空白PNG图片
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestImageValidatorLimits(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Upload.MinImageSide = 20
+	cfg.Upload.MaxImagePixels = 100 * 100
+	cfg.Upload.MaxLongImagePixels = 100 * 1000
+	cfg.OCR.Tiling.TileHeight = 200
+	v := NewImageValidator(cfg)
+
+	tests := []struct {
+		name  string
+		data  []byte
+		valid bool
+	}{
+		{name: "empty", data: nil},
+		{name: "not an image", data: []byte("%PDF-1.4 not an image")},
+		{name: "too small", data: encodePNG(t, 10, 100)},
+		{name: "ok", data: encodePNG(t, 100, 100), valid: true},
+		{name: "too many pixels", data: encodePNG(t, 101, 100)},
+		{name: "long screenshot", data: encodePNG(t, 50, 1000), valid: true},
+		{name: "long screenshot too wide for a tile", data: encodePNG(t, 60, 1000)},
+		{name: "long screenshot too many pixels", data: encodePNG(t, 50, 2001)},
+		{name: "truncated header", data: encodePNG(t, 100, 100)[:20]},
+	}
+	for _, tt := range tests {
+		info, err := v.Validate(tt.data)
+		if tt.valid {
+			if err != nil {
+				t.Errorf("%s: Validate() error: %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: Validate() = %+v, want error", tt.name, info)
+			continue
+		}
+		if errors.HTTPStatus(err) != http.StatusBadRequest || !isRejectedImage(err) {
+			t.Errorf("%s: Validate() error = %v, want a rejected client error", tt.name, err)
+		}
+	}
+}
+
+func TestImageValidatorByteLimits(t *testing.T) {
+	small := encodePNG(t, 100, 100)
+	long := encodePNG(t, 100, 3000)
+
+	cfg := &config.Config{}
+	cfg.Upload.MaxImageBytes = int64(len(small)) - 1
+	cfg.Upload.MaxLongImageBytes = int64(len(long))
+	cfg.OCR.Tiling.TileHeight = 1000
+	v := NewImageValidator(cfg)
+
+	if _, err := v.Validate(small); err == nil {
+		t.Errorf("Validate() accepted an image over the single image byte limit")
+	}
+	// 长截图切图后提交，使用长截图的大小上限
+	if _, err := v.Validate(long); err != nil {
+		t.Errorf("Validate() long screenshot error: %v", err)
+	}
+	if got := v.MaxBytes(); got != int64(len(long)) {
+		t.Errorf("MaxBytes() = %d, want %d", got, len(long))
+	}
+}
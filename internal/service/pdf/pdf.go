@@ -0,0 +1,198 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"domain-analyzer/config"
+	"domain-analyzer/internal/pkg/domainutil"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 默认参数
+const (
+	defaultDPI      = 200
+	defaultMaxPages = 20
+	defaultTimeout  = 60 * time.Second
+
+	// minTextRunes 页面文字层中非空白字符少于该数量时视为扫描件，需要OCR识别
+	minTextRunes = 16
+)
+
+var (
+	// ErrToolNotFound 未安装 poppler-utils 时返回
+	ErrToolNotFound = errors.New("pdftotext/pdftoppm not found, please install poppler-utils")
+	// ErrTooManyPages PDF的页数超过 MaxPages 时返回
+	ErrTooManyPages = errors.New("pdf has too many pages")
+)
+
+// Page 表示PDF的一页，有文字层时 Lines 为文字内容，否则 Image 为渲染后的PNG图片
+type Page struct {
+	Number int // 页码，从1开始
+	Lines  []string
+	Image  []byte
+}
+
+// Extractor 调用 poppler-utils 的 pdftotext、pdftoppm 命令读取PDF
+type Extractor struct {
+	pdftotext string
+	pdftoppm  string
+	dpi       int
+	maxPages  int
+	timeout   time.Duration
+}
+
+// NewExtractor 根据配置创建PDF读取器，命令是否存在在使用时检查
+func NewExtractor(config *config.Config) *Extractor {
+	cfg := config.PDF
+	e := &Extractor{
+		pdftotext: "pdftotext",
+		pdftoppm:  "pdftoppm",
+		dpi:       defaultDPI,
+		maxPages:  defaultMaxPages,
+		timeout:   defaultTimeout,
+	}
+	if cfg.PdftotextPath != "" {
+		e.pdftotext = cfg.PdftotextPath
+	}
+	if cfg.PdftoppmPath != "" {
+		e.pdftoppm = cfg.PdftoppmPath
+	}
+	if cfg.DPI > 0 {
+		e.dpi = cfg.DPI
+	}
+	if cfg.MaxPages > 0 {
+		e.maxPages = cfg.MaxPages
+	}
+	if cfg.TimeoutSeconds > 0 {
+		e.timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+	return e
+}
+
+// MaxPages 返回最多读取的页数
+func (e *Extractor) MaxPages() int {
+	return e.maxPages
+}
+
+// Extract 读取PDF的每一页：文字层中有域名时直接使用文字，否则渲染为图片
+// 扫描件上常有页眉、页脚等少量文字，文字层没有域名时不能说明页面中没有域名，需要OCR识别
+// 页数超过 MaxPages 时返回 ErrTooManyPages
+func (e *Extractor) Extract(ctx context.Context, data []byte) ([]Page, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	// poppler 需要可以随机读取的文件，先写入临时目录
+	dir, err := os.MkdirTemp("", "domain-analyzer-pdf-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	input := filepath.Join(dir, "input.pdf")
+	if err := os.WriteFile(input, data, 0o600); err != nil {
+		return nil, err
+	}
+
+	// pdftotext 以换页符分隔每一页，多读取一页用于判断是否超过页数限制
+	out, err := e.run(ctx, e.pdftotext, "-layout", "-enc", "UTF-8", "-l", strconv.Itoa(e.maxPages+1), input, "-")
+	if err != nil {
+		return nil, err
+	}
+	texts := strings.Split(string(out), "\f")
+	if n := len(texts); n > 0 && strings.TrimSpace(texts[n-1]) == "" {
+		texts = texts[:n-1]
+	}
+	if len(texts) > e.maxPages {
+		return nil, fmt.Errorf("%w: more than %d pages", ErrTooManyPages, e.maxPages)
+	}
+
+	pages := make([]Page, 0, len(texts))
+	for i, text := range texts {
+		page := Page{Number: i + 1}
+		if lines := textLines(text); hasDomains(lines) {
+			page.Lines = lines
+		} else if page.Image, err = e.render(ctx, dir, input, page.Number); err != nil {
+			return nil, err
+		}
+		pages = append(pages, page)
+	}
+	return pages, nil
+}
+
+// render 将指定页渲染为PNG图片
+func (e *Extractor) render(ctx context.Context, dir, input string, number int) ([]byte, error) {
+	prefix := filepath.Join(dir, fmt.Sprintf("page-%d", number))
+	page := strconv.Itoa(number)
+	if _, err := e.run(ctx, e.pdftoppm, "-png", "-r", strconv.Itoa(e.dpi), "-f", page, "-l", page, input, prefix); err != nil {
+		return nil, err
+	}
+
+	// pdftoppm 会在文件名后追加补零的页码，例如 page-3-03.png
+	files, err := filepath.Glob(prefix + "-*.png")
+	if err != nil || len(files) == 0 {
+		return nil, fmt.Errorf("pdftoppm produced no image for page %d", number)
+	}
+	sort.Strings(files)
+	return os.ReadFile(files[0])
+}
+
+// run 执行命令并返回标准输出
+func (e *Extractor) run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return nil, ErrToolNotFound
+	}
+	cmd := exec.CommandContext(ctx, path, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(name), ctx.Err())
+		}
+		return nil, fmt.Errorf("%s: %v: %s", filepath.Base(name), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// textLines 返回页面文字层中的非空行，文字过少时视为没有文字层返回nil
+func textLines(text string) []string {
+	if countTextRunes(text) < minTextRunes {
+		return nil
+	}
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// hasDomains 判断文字中是否有域名
+func hasDomains(lines []string) bool {
+	if len(lines) == 0 {
+		return false
+	}
+	domains, _ := domainutil.ExtractDomains(lines)
+	return len(domains) > 0
+}
+
+// countTextRunes 统计非空白字符数量
+func countTextRunes(text string) int {
+	n := 0
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			n++
+		}
+	}
+	return n
+}
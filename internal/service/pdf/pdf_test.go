@@ -0,0 +1,28 @@
+package pdf
+
+import "testing"
+
+func TestTextLayerPages(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{name: "text page with domains", text: "Expired domains\n\n  example.com    $100\n  example.net    $200\n", want: true},
+		{name: "scanned page with footer", text: "\n\n  Confidential - Page 3 of 12 - Printed 2024\n", want: false},
+		{name: "scanned page", text: "  \n ", want: false},
+		{name: "short text with domain", text: "a.com", want: false},
+	}
+	for _, tt := range tests {
+		if got := hasDomains(textLines(tt.text)); got != tt.want {
+			t.Errorf("%s: hasDomains(textLines()) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTextLines(t *testing.T) {
+	lines := textLines("  first line of text  \n\n\tsecond line\n")
+	if len(lines) != 2 || lines[0] != "first line of text" || lines[1] != "second line" {
+		t.Errorf("textLines() = %q", lines)
+	}
+}
@@ -0,0 +1,257 @@
+package table
+
+import (
+	"domain-analyzer/internal/pkg/domainutil"
+	"domain-analyzer/internal/service/ocr"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 常见拍卖、过期域名列表的列，列名统一为以下键
+const (
+	ColumnDomain    = "domain"
+	ColumnPrice     = "price"
+	ColumnBids      = "bids"
+	ColumnEndTime   = "end_time"
+	ColumnBacklinks = "backlinks"
+	ColumnAge       = "age"
+	ColumnRating    = "rating"
+	ColumnTraffic   = "traffic"
+)
+
+// headerKeywords 表头关键词，按顺序匹配，例如 "current bid" 先匹配为价格，"bids" 再匹配为出价次数；
+// "domain age"、"domain rating" 这类更具体的列排在域名列之前。
+// wholeWord 的关键词只匹配整个表头或表头中的一个单词，其余关键词较长时按子串匹配
+var headerKeywords = []struct {
+	column    string
+	keywords  []string
+	wholeWord bool
+}{
+	{ColumnAge, []string{"age", "年龄", "注册时间"}, false},
+	{ColumnRating, []string{"rating", "authority", "dr", "da", "评分"}, false},
+	{ColumnDomain, []string{"domain", "域名", "name"}, true},
+	{ColumnPrice, []string{"price", "current bid", "bid price", "价格", "现价", "当前价", "金额", "buy now"}, false},
+	{ColumnBids, []string{"bids", "bid", "出价"}, false},
+	{ColumnEndTime, []string{"end", "ends", "time left", "expire", "drop", "剩余", "结束", "截止"}, false},
+	{ColumnBacklinks, []string{"backlink", "bl", "外链", "反链"}, false},
+	{ColumnTraffic, []string{"traffic", "visits", "流量"}, false},
+}
+
+// priceRegex 没有表头时，带货币符号的单元格视为价格
+var priceRegex = regexp.MustCompile(`^(?:[$€£¥￥]\s*[\d,]+(?:\.\d+)?|[\d,]+(?:\.\d+)?\s*(?:USD|EUR|CNY|元))$`)
+
+// Cell 表示表格中的一个单元格，对应一条OCR识别结果
+type Cell struct {
+	Index  int    `json:"index"` // 在OCR结果中的下标，与提取域名时的 TextIndex 一致
+	Text   string `json:"text"`
+	Column string `json:"column,omitempty"` // 所属列的键，没有识别出表头时为空
+	left   int
+	right  int
+	top    int
+	bottom int
+}
+
+// Column 表示从表头识别出的一列
+type Column struct {
+	Key    string `json:"key"`
+	Header string `json:"header"`
+	left   int
+	right  int
+}
+
+// Table 表示按文本框位置解析出的表格
+type Table struct {
+	Columns []Column    `json:"columns,omitempty"`
+	Rows    [][]Cell    `json:"rows"`
+	rowOf   map[int]int // OCR结果下标 -> 行下标
+}
+
+// Parse 根据文本框的位置将OCR结果按行分组，并识别表头
+// offset 为 detections 中第一条结果在全部OCR结果中的下标，批量上传时每张图片分别解析；没有坐标的识别结果会被忽略
+func Parse(detections []ocr.Detection, offset int) *Table {
+	var cells []Cell
+	for i, d := range detections {
+		if len(d.Polygon) == 0 || strings.TrimSpace(d.Text) == "" {
+			continue
+		}
+		c := Cell{Index: offset + i, Text: strings.TrimSpace(d.Text), left: d.Polygon[0].X, right: d.Polygon[0].X, top: d.Polygon[0].Y, bottom: d.Polygon[0].Y}
+		for _, p := range d.Polygon[1:] {
+			c.left, c.right = minInt(c.left, p.X), maxInt(c.right, p.X)
+			c.top, c.bottom = minInt(c.top, p.Y), maxInt(c.bottom, p.Y)
+		}
+		cells = append(cells, c)
+	}
+
+	t := &Table{Rows: groupRows(cells), rowOf: make(map[int]int)}
+	t.detectHeader()
+	for i, row := range t.Rows {
+		for j := range row {
+			row[j].Column = t.columnOf(row[j])
+			t.rowOf[row[j].Index] = i
+		}
+	}
+	return t
+}
+
+// groupRows 按纵向中心位置将单元格分组为行，行内按横坐标排序
+// 单元格的纵向中心落在当前行第一个单元格的上下边界之内时属于同一行
+func groupRows(cells []Cell) [][]Cell {
+	sort.SliceStable(cells, func(i, j int) bool {
+		return cells[i].top+cells[i].bottom < cells[j].top+cells[j].bottom
+	})
+
+	var rows [][]Cell
+	for _, c := range cells {
+		center := (c.top + c.bottom) / 2
+		if n := len(rows); n > 0 {
+			first := rows[n-1][0]
+			if center >= first.top && center <= first.bottom {
+				rows[n-1] = append(rows[n-1], c)
+				continue
+			}
+		}
+		rows = append(rows, []Cell{c})
+	}
+	for _, row := range rows {
+		sort.SliceStable(row, func(i, j int) bool { return row[i].left < row[j].left })
+	}
+	return rows
+}
+
+// detectHeader 将第一个包含至少两个表头关键词且不包含域名的行识别为表头
+func (t *Table) detectHeader() {
+	for i, row := range t.Rows {
+		var columns []Column
+		known := 0
+		for _, c := range row {
+			if d, _ := domainutil.ParseDomain(c.Text); d != nil {
+				columns = nil
+				break
+			}
+			key := headerColumn(c.Text)
+			if key != "" {
+				known++
+			} else {
+				key = strings.ToLower(c.Text)
+			}
+			columns = append(columns, Column{Key: key, Header: c.Text, left: c.left, right: c.right})
+		}
+		if columns != nil && known >= 2 {
+			t.Columns = columns
+			t.Rows = append(t.Rows[:i:i], t.Rows[i+1:]...)
+			return
+		}
+	}
+}
+
+// headerColumn 根据表头文本返回列的键，不是常见列时返回空字符串
+func headerColumn(header string) string {
+	text := strings.ToLower(strings.TrimSpace(header))
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ' ' || r == '#' || r == '(' || r == ')' || r == '/' || r == ':'
+	})
+	for _, h := range headerKeywords {
+		for _, keyword := range h.keywords {
+			// 英文短关键词需要完整匹配单词，避免 "bl" 匹配 "table" 之类的误判
+			if h.wholeWord || (len(keyword) <= 3 && isASCII(keyword)) {
+				if text == keyword {
+					return h.column
+				}
+				for _, w := range words {
+					if w == keyword {
+						return h.column
+					}
+				}
+				continue
+			}
+			if strings.Contains(text, keyword) {
+				return h.column
+			}
+		}
+	}
+	return ""
+}
+
+// columnOf 返回单元格所属的列：横向范围有重叠的列中重叠最多的一列，没有重叠时取中心最近的一列
+func (t *Table) columnOf(c Cell) string {
+	if len(t.Columns) == 0 {
+		if priceRegex.MatchString(c.Text) {
+			return ColumnPrice
+		}
+		return ""
+	}
+
+	best, bestOverlap, bestDistance := "", 0, -1
+	center := (c.left + c.right) / 2
+	for _, col := range t.Columns {
+		overlap := minInt(c.right, col.right) - maxInt(c.left, col.left)
+		distance := absInt(center - (col.left+col.right)/2)
+		if overlap > bestOverlap || (bestOverlap == 0 && overlap <= 0 && (bestDistance < 0 || distance < bestDistance)) {
+			best, bestOverlap, bestDistance = col.Key, maxInt(overlap, 0), distance
+		}
+	}
+	return best
+}
+
+// Metadata 返回与指定OCR结果同一行的其他列，键为列的键，同一列有多个单元格时以空格连接
+// 该结果不在任何行中或同一行没有其他列时返回nil
+func (t *Table) Metadata(index int) map[string]string {
+	i, ok := t.rowOf[index]
+	if !ok {
+		return nil
+	}
+
+	var own string
+	for _, c := range t.Rows[i] {
+		if c.Index == index {
+			own = c.Column
+		}
+	}
+
+	metadata := make(map[string]string)
+	for _, c := range t.Rows[i] {
+		if c.Index == index || c.Column == "" || c.Column == ColumnDomain || (own != "" && c.Column == own) {
+			continue
+		}
+		if v, ok := metadata[c.Column]; ok {
+			metadata[c.Column] = v + " " + c.Text
+		} else {
+			metadata[c.Column] = c.Text
+		}
+	}
+	if len(metadata) == 0 {
+		return nil
+	}
+	return metadata
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func absInt(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
@@ -0,0 +1,155 @@
+package table
+
+import (
+	"domain-analyzer/internal/model"
+	"domain-analyzer/internal/service/ocr"
+	"testing"
+)
+
+// box 生成横向范围为 left~right、纵向范围为 top~bottom 的识别结果
+func box(text string, left, right, top, bottom int) ocr.Detection {
+	return ocr.Detection{Text: text, Polygon: []model.Point{
+		{X: left, Y: top}, {X: right, Y: top}, {X: right, Y: bottom}, {X: left, Y: bottom},
+	}}
+}
+
+func TestHeaderColumn(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "Domain", want: ColumnDomain},
+		{header: "Domain Name", want: ColumnDomain},
+		{header: "域名", want: ColumnDomain},
+		{header: "Domain Age", want: ColumnAge},
+		{header: "域名年龄", want: ColumnAge},
+		{header: "Domain Rating", want: ColumnRating},
+		{header: "DR", want: ColumnRating},
+		{header: "Current Bid", want: ColumnPrice},
+		{header: "Bids", want: ColumnBids},
+		{header: "BL", want: ColumnBacklinks},
+		{header: "Time Left", want: ColumnEndTime},
+		{header: "Domains for sale", want: ""},
+		{header: "Nameserver", want: ""},
+		{header: "Table", want: ""},
+	}
+	for _, tt := range tests {
+		if got := headerColumn(tt.header); got != tt.want {
+			t.Errorf("headerColumn(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestGroupRows(t *testing.T) {
+	cells := []Cell{
+		{Index: 0, Text: "$100", left: 200, right: 260, top: 52, bottom: 68},
+		{Index: 1, Text: "a.com", left: 0, right: 80, top: 50, bottom: 70},
+		{Index: 2, Text: "b.com", left: 0, right: 80, top: 100, bottom: 120},
+		{Index: 3, Text: "Domain", left: 0, right: 80, top: 0, bottom: 20},
+		{Index: 4, Text: "$200", left: 200, right: 260, top: 105, bottom: 121},
+	}
+	rows := groupRows(cells)
+	want := [][]int{{3}, {1, 0}, {2, 4}}
+	if len(rows) != len(want) {
+		t.Fatalf("groupRows() = %d rows, want %d", len(rows), len(want))
+	}
+	for i, row := range rows {
+		if len(row) != len(want[i]) {
+			t.Fatalf("groupRows() row %d = %d cells, want %d", i, len(row), len(want[i]))
+		}
+		for j, c := range row {
+			if c.Index != want[i][j] {
+				t.Errorf("groupRows() row %d cell %d = %d, want %d", i, j, c.Index, want[i][j])
+			}
+		}
+	}
+}
+
+func TestDetectHeader(t *testing.T) {
+	table := &Table{Rows: [][]Cell{
+		{{Text: "Expired domains"}},
+		{{Text: "Domain", left: 0, right: 80}, {Text: "Price", left: 200, right: 260}},
+		{{Text: "a.com", left: 0, right: 80}, {Text: "$100", left: 200, right: 260}},
+	}}
+	table.detectHeader()
+	if len(table.Columns) != 2 || table.Columns[0].Key != ColumnDomain || table.Columns[1].Key != ColumnPrice {
+		t.Fatalf("detectHeader() columns = %+v", table.Columns)
+	}
+	if len(table.Rows) != 2 || table.Rows[1][0].Text != "a.com" {
+		t.Errorf("detectHeader() should remove the header row, rows = %+v", table.Rows)
+	}
+
+	// 包含域名的行不是表头
+	table = &Table{Rows: [][]Cell{
+		{{Text: "bid.com"}, {Text: "Price"}, {Text: "Bids"}},
+	}}
+	table.detectHeader()
+	if table.Columns != nil {
+		t.Errorf("detectHeader() on a row with a domain = %+v, want no columns", table.Columns)
+	}
+}
+
+func TestColumnOf(t *testing.T) {
+	table := &Table{Columns: []Column{
+		{Key: ColumnDomain, left: 0, right: 100},
+		{Key: ColumnPrice, left: 200, right: 260},
+		{Key: ColumnBids, left: 300, right: 340},
+	}}
+	tests := []struct {
+		cell Cell
+		want string
+	}{
+		{cell: Cell{left: 10, right: 90}, want: ColumnDomain},
+		{cell: Cell{left: 190, right: 250}, want: ColumnPrice},
+		{cell: Cell{left: 80, right: 230}, want: ColumnPrice},
+		{cell: Cell{left: 280, right: 290}, want: ColumnBids},
+		{cell: Cell{left: 120, right: 130}, want: ColumnDomain},
+	}
+	for _, tt := range tests {
+		if got := table.columnOf(tt.cell); got != tt.want {
+			t.Errorf("columnOf(%d~%d) = %q, want %q", tt.cell.left, tt.cell.right, got, tt.want)
+		}
+	}
+
+	// 没有表头时只识别价格
+	empty := &Table{}
+	if got := empty.columnOf(Cell{Text: "$1,200"}); got != ColumnPrice {
+		t.Errorf("columnOf($1,200) without header = %q, want %q", got, ColumnPrice)
+	}
+	if got := empty.columnOf(Cell{Text: "12"}); got != "" {
+		t.Errorf("columnOf(12) without header = %q, want empty", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	detections := []ocr.Detection{
+		box("Domain", 0, 80, 0, 20),
+		box("Domain Age", 100, 180, 0, 20),
+		box("Price", 200, 260, 0, 20),
+		box("a.com", 0, 80, 50, 70),
+		box("5 years", 100, 170, 50, 70),
+		box("$100", 200, 250, 52, 68),
+		{Text: "no polygon"},
+		box("b.com", 0, 80, 100, 120),
+		box("$200", 200, 250, 100, 120),
+	}
+	table := Parse(detections, 10)
+
+	if len(table.Columns) != 3 || table.Columns[1].Key != ColumnAge {
+		t.Fatalf("Parse() columns = %+v", table.Columns)
+	}
+	if len(table.Rows) != 2 {
+		t.Fatalf("Parse() = %d rows, want 2", len(table.Rows))
+	}
+
+	metadata := table.Metadata(13)
+	if metadata[ColumnAge] != "5 years" || metadata[ColumnPrice] != "$100" || len(metadata) != 2 {
+		t.Errorf("Metadata(a.com) = %v", metadata)
+	}
+	if metadata := table.Metadata(17); metadata[ColumnPrice] != "$200" {
+		t.Errorf("Metadata(b.com) = %v", metadata)
+	}
+	if metadata := table.Metadata(16); metadata != nil {
+		t.Errorf("Metadata(no polygon) = %v, want nil", metadata)
+	}
+}
@@ -0,0 +1,128 @@
+package verdict
+
+import (
+	"domain-analyzer/internal/model"
+	"fmt"
+	"time"
+)
+
+const (
+	ruleArchiveAge = "archive_age"
+	ruleTraffic    = "traffic"
+)
+
+// archiveAgeRule 要求域名首次被 Web Archive 收录的时间距今不少于 DaysThreshold 天
+type archiveAgeRule struct {
+	thresholdDays int
+}
+
+func (r *archiveAgeRule) Name() string {
+	return ruleArchiveAge
+}
+
+func (r *archiveAgeRule) Evaluate(analysis *model.DomainAnalysis, now time.Time) model.RuleResult {
+	result := model.RuleResult{Rule: r.Name()}
+
+	if r.thresholdDays <= 0 {
+		result.Status = model.RuleStatusSkip
+		result.Message = "未配置收录天数阈值"
+		return result
+	}
+
+	switch analysis.Sources.WebArchive.State {
+	case model.SourceStateNotFound:
+		result.Status = model.RuleStatusFail
+		result.Message = "从未被 Web Archive 收录"
+		return result
+	case model.SourceStateError:
+		result.Status = model.RuleStatusSkip
+		result.Message = "Web Archive 查询失败，无法判定收录时间"
+		return result
+	}
+
+	createTime := analysis.WebArchiveResponse.CreateTime
+	if createTime.IsZero() {
+		result.Status = model.RuleStatusSkip
+		result.Message = "缺少 Web Archive 收录数据"
+		return result
+	}
+
+	days := int(now.Sub(createTime).Hours() / 24)
+	if days >= r.thresholdDays {
+		result.Status = model.RuleStatusPass
+		result.Message = fmt.Sprintf("首次收录于 %d 天前，不低于阈值 %d 天", days, r.thresholdDays)
+	} else {
+		result.Status = model.RuleStatusFail
+		result.Message = fmt.Sprintf("首次收录于 %d 天前，低于阈值 %d 天", days, r.thresholdDays)
+	}
+	return result
+}
+
+// trafficRule 要求域名的月均访问量不少于 TrafficThreshold
+type trafficRule struct {
+	threshold int64
+}
+
+func (r *trafficRule) Name() string {
+	return ruleTraffic
+}
+
+func (r *trafficRule) Evaluate(analysis *model.DomainAnalysis, _ time.Time) model.RuleResult {
+	result := model.RuleResult{Rule: r.Name()}
+
+	if r.threshold <= 0 {
+		result.Status = model.RuleStatusSkip
+		result.Message = "未配置流量阈值"
+		return result
+	}
+
+	switch analysis.Sources.SimilarWeb.State {
+	case model.SourceStateNotFound:
+		result.Status = model.RuleStatusFail
+		result.Message = fmt.Sprintf("SimilarWeb 没有访问量数据，低于阈值 %d", r.threshold)
+		return result
+	case model.SourceStateError:
+		result.Status = model.RuleStatusSkip
+		result.Message = "SimilarWeb 查询失败，无法判定流量"
+		return result
+	}
+
+	visits, ok := MonthlyVisits(analysis.TotalTrafficAndEngagementResp)
+	if !ok {
+		result.Status = model.RuleStatusSkip
+		result.Message = "缺少 SimilarWeb 流量数据"
+		return result
+	}
+
+	if int64(visits) >= r.threshold {
+		result.Status = model.RuleStatusPass
+		result.Message = fmt.Sprintf("月均访问量 %.0f，不低于阈值 %d", visits, r.threshold)
+	} else {
+		result.Status = model.RuleStatusFail
+		result.Message = fmt.Sprintf("月均访问量 %.0f，低于阈值 %d", visits, r.threshold)
+	}
+	return result
+}
+
+// MonthlyVisits 根据 SimilarWeb 返回的访问数据估算月均访问量
+// 非月粒度的数据按平均每月 30 天折算；没有任何数据点时第二个返回值为 false
+func MonthlyVisits(resp model.TotalTrafficAndEngagementResp) (float64, bool) {
+	if len(resp.Visits) == 0 {
+		return 0, false
+	}
+
+	var total float64
+	for _, v := range resp.Visits {
+		total += v.Visits
+	}
+	avg := total / float64(len(resp.Visits))
+
+	switch resp.Meta.Request.Granularity {
+	case "daily":
+		return avg * 30, true
+	case "weekly":
+		return avg * 30 / 7, true
+	default:
+		return avg, true
+	}
+}
@@ -0,0 +1,54 @@
+package verdict
+
+import (
+	"domain-analyzer/config"
+	"domain-analyzer/internal/model"
+	"time"
+)
+
+// Rule 定义单条判定规则
+type Rule interface {
+	// Name 返回规则名称，会出现在判定结果中
+	Name() string
+	// Evaluate 根据分析结果给出该规则的判定
+	Evaluate(analysis *model.DomainAnalysis, now time.Time) model.RuleResult
+}
+
+// Engine 根据配置的阈值对域名分析结果给出通过/不通过的判定
+type Engine struct {
+	rules []Rule
+	now   func() time.Time
+}
+
+// NewEngine 根据配置创建判定引擎
+func NewEngine(config *config.Config) *Engine {
+	return &Engine{
+		rules: []Rule{
+			&archiveAgeRule{thresholdDays: config.Analysis.DaysThreshold},
+			&trafficRule{threshold: config.Analysis.TrafficThreshold},
+		},
+		now: time.Now,
+	}
+}
+
+// Evaluate 对单个域名的分析结果逐条执行规则
+// 只要有一条规则未通过即判定为不通过；所有规则都被跳过时同样不通过
+func (e *Engine) Evaluate(analysis *model.DomainAnalysis) model.Verdict {
+	now := e.now()
+	verdict := model.Verdict{}
+
+	var passed, failed int
+	for _, rule := range e.rules {
+		result := rule.Evaluate(analysis, now)
+		switch result.Status {
+		case model.RuleStatusPass:
+			passed++
+		case model.RuleStatusFail:
+			failed++
+		}
+		verdict.Reasons = append(verdict.Reasons, result)
+	}
+
+	verdict.Passed = failed == 0 && passed > 0
+	return verdict
+}
@@ -1,12 +1,18 @@
 package main
 
 import (
+	"database/sql"
 	"domain-analyzer/config"
 	"domain-analyzer/internal/handler"
+	"domain-analyzer/internal/pkg/domainutil"
 	"domain-analyzer/internal/pkg/logger"
+	"domain-analyzer/internal/repository"
+	"domain-analyzer/internal/service/analysis"
+	"domain-analyzer/internal/service/correction"
 	"domain-analyzer/internal/service/domain"
 	"domain-analyzer/internal/service/ocr"
 	"log"
+	"os"
 	"path/filepath"
 
 	"domain-analyzer/internal/pkg/errors"
@@ -21,11 +27,40 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// 子命令: migrate up/down/status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			log.Fatalf("Migrate failed: %v", err)
+		}
+		return
+	}
+
+	// 子命令: psl update
+	if len(os.Args) > 1 && os.Args[1] == "psl" {
+		if err := runPSL(cfg, os.Args[2:]); err != nil {
+			log.Fatalf("PSL command failed: %v", err)
+		}
+		return
+	}
+
 	// 初始化日志
 	logger.InitLogger()
 
+	// 加载本地公共后缀列表，不存在时使用内嵌的列表
+	if err := domainutil.InitPublicSuffixList(); err != nil {
+		logger.Fatalf("Failed to load public suffix list: %v", err)
+	}
+	if path := cfg.Domain.PublicSuffixListPath; path != "" {
+		if err := domainutil.LoadPublicSuffixList(path); err != nil {
+			if !os.IsNotExist(err) {
+				logger.Fatalf("Failed to load public suffix list: %v", err)
+			}
+			logger.Warnf("Public suffix list %s not found, using embedded list", path)
+		}
+	}
+
 	// 初始化OCR服务
-	ocrService, err := ocr.NewTencentOCR(cfg)
+	ocrService, err := ocr.NewOCRService(cfg)
 	if err != nil {
 		logger.Fatalf("Failed to initialize OCR service: %v", err)
 	}
@@ -33,8 +68,36 @@ func main() {
 	// 初始化WebArchive服务
 	domain.InitWebArchive(cfg)
 
+	// 初始化分析结果存储，未配置DSN时不保存
+	var repo repository.AnalysisRepository
+	var db *sql.DB
+	if cfg.Database.DSN != "" {
+		db, err = repository.Open(cfg)
+		if err != nil {
+			logger.Fatalf("Failed to connect database: %v", err)
+		}
+		defer db.Close()
+		repo = repository.NewAnalysisRepository(db)
+	}
+
+	// 初始化OCR结果缓存，配置数据库时同时缓存到数据库中
+	if cfg.OCR.Cache.Enabled {
+		caches := []ocr.Cache{ocr.NewMemoryCache(cfg.OCR.Cache.MaxEntries)}
+		if db != nil {
+			caches = append(caches, repository.NewOCRCache(db))
+		}
+		ocrService = ocr.NewCachedOCR(cfg, ocrService, caches...)
+	}
+
+	// 初始化OCR纠错
+	var corrector *correction.Corrector
+	if cfg.Correction.Enabled {
+		corrector = correction.NewCorrector(cfg, correction.NewWebArchiveVerifier(domain.GetWebArchive()))
+	}
+
 	// 初始化handler
-	h := handler.NewUploadHandler(ocrService)
+	pipeline := handler.NewAnalysisPipeline(analysis.NewAnalyzer(cfg), corrector, repo)
+	h := handler.NewUploadHandler(cfg, ocrService, pipeline)
 
 	r := gin.Default()
 
@@ -55,21 +118,12 @@ func main() {
 
 		// 获取错误信息
 		if err, exists := c.Get("handler_error"); exists {
+			// 完整的错误链只记录到日志中，响应只返回错误信息，避免泄露服务内部细节
 			logger.Errorf("handler error: %+v", err)
 			errObj := err.(error)
-			resp := Response{
-				Message: errObj.Error(),
-			}
-
-			if errors.IsClientError(errObj) {
-				c.JSON(400, resp)
-			} else if errors.IsServerError(errObj) {
-				c.JSON(500, resp)
-			} else {
-				// 未知错误类型按服务端错误处理
-				resp.Message = "Internal Server Error"
-				c.JSON(500, resp)
-			}
+			c.JSON(errors.HTTPStatus(errObj), Response{
+				Message: errors.Message(errObj),
+			})
 			return
 		}
 
@@ -96,7 +150,12 @@ func main() {
 	// 包装handler接口调用的辅助函数
 	wrapHandler := func(h handler.Handler) gin.HandlerFunc {
 		return func(c *gin.Context) {
-			result, err := h.Handle(c.Request.Context(), c.Request)
+			params := make(map[string]string, len(c.Params))
+			for _, p := range c.Params {
+				params[p.Key] = p.Value
+			}
+			ctx := handler.WithPathParams(c.Request.Context(), params)
+			result, err := h.Handle(ctx, c.Request)
 			if err != nil {
 				c.Set("handler_error", err)
 				return
@@ -106,6 +165,13 @@ func main() {
 	}
 
 	r.POST("/upload", wrapHandler(h))
+	r.POST("/api/analyze", wrapHandler(handler.NewAnalyzeHandler(pipeline)))
+
+	// 历史分析结果查询，需要配置数据库
+	if repo != nil {
+		r.GET("/api/analyses", wrapHandler(handler.NewListAnalysesHandler(repo)))
+		r.GET("/api/domains/:name", wrapHandler(handler.NewDomainHistoryHandler(repo)))
+	}
 
 	log.Fatal(r.Run(":" + cfg.Server.Port))
 }
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"domain-analyzer/config"
+	"domain-analyzer/internal/pkg/domainutil"
+	"fmt"
+	"time"
+)
+
+const pslUsage = `用法: domain-analyzer psl <command>
+
+command:
+  update      下载最新的公共后缀列表到 domain.public_suffix_list_path`
+
+// runPSL 执行 psl 子命令
+func runPSL(cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "update" {
+		return fmt.Errorf("unknown psl command\n%s", pslUsage)
+	}
+
+	path := cfg.Domain.PublicSuffixListPath
+	if path == "" {
+		return fmt.Errorf("domain.public_suffix_list_path is not configured")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := domainutil.UpdatePublicSuffixList(ctx, path); err != nil {
+		return err
+	}
+	fmt.Printf("public suffix list updated: %s\n", path)
+	return nil
+}